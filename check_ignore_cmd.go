@@ -0,0 +1,118 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// checkIgnoreFlags defines the flags of the check-ignore command
+func checkIgnoreFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("check-ignore", flag.ContinueOnError)
+	flags.String("path", ".", "Directory being organized; patterns are matched relative to it")
+	flags.String("profile", "", "Named profile from the config file whose ignore patterns also apply")
+	flags.String("config", "", "Config file to load (default: first of config/config.{json,yaml,yml,toml})")
+	flags.String("ignore-file", ".organizerignore", "Ignore file with patterns to skip")
+	flags.Bool("verbose", false, "Also print the pattern that matched each ignored path")
+	flags.Bool("v", false, "Shorthand for --verbose")
+	addOutputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer check-ignore [--path directory] [--profile name] [-v] [--output text|json|ndjson] <path> [path ...]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runCheckIgnoreCommand reports which of the given paths are excluded by the
+// ignore file and profile patterns. Like git check-ignore it exits with 0 if
+// at least one path is ignored and 1 if none is.
+func runCheckIgnoreCommand(args []string) int {
+	flags := checkIgnoreFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	settings := gatherSettings(flags, nil)
+	events := eventOutputFor(settings)
+	if events == nil {
+		return exitUsage
+	}
+	verbose, err := settings.Bool("verbose")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitConfigError
+	}
+
+	config, err := loadConfig(settings)
+	if err != nil {
+		// A config file that was asked for must load; a discovered one may not
+		if settings.String("config") != "" {
+			fmt.Fprintf(os.Stderr, "Error: could not load config file: %v\n", err)
+			return exitConfigError
+		}
+		fmt.Fprintf(os.Stderr, "Warning: Could not load config file: %v\n", err)
+	}
+
+	rootPath, err := filepath.Abs(settings.String("path"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	_, profile, err := buildExtensionMapping(config, settings.String("profile"), nil, io.Discard)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitConfigError
+	}
+
+	ignoreManager := utils.NewIgnoreManager(rootPath)
+	ignoreManager.SetOutput(io.Discard)
+	if err := ignoreManager.LoadIgnoreFile(settings.String("ignore-file")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	ignoreManager.AddPatterns(profile.IgnorePatterns)
+
+	ignored := 0
+	for _, path := range flags.Args() {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		pattern, matched := ignoreManager.MatchingPatternOrParent(absPath)
+		if !matched {
+			continue
+		}
+		ignored++
+		if verbose {
+			fmt.Fprintf(events.Text(), "%s\t%s\n", pattern, path)
+		} else {
+			fmt.Fprintln(events.Text(), path)
+		}
+		events.Emit(organizer.Event{Type: organizer.EventSkipped, Path: path, Reason: organizer.ReasonIgnored, Metadata: map[string]string{"pattern": pattern}})
+	}
+	events.SetSummary(checkIgnoreSummary{Checked: flags.NArg(), Ignored: ignored})
+	events.Finish()
+
+	if ignored == 0 {
+		return 1
+	}
+	return exitOK
+}
+
+// checkIgnoreSummary is the summary of check-ignore in json and ndjson output
+type checkIgnoreSummary struct {
+	Checked int `json:"checked"`
+	Ignored int `json:"ignored"`
+}
@@ -0,0 +1,548 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// completeCommand is the hidden command the completion scripts call to get the
+// candidates for the word being completed
+const completeCommand = "__complete"
+
+// completionDirective tells the completion script what to do with the candidates.
+// It is printed as the last line of the __complete output, prefixed with ':'.
+type completionDirective string
+
+const (
+	// completeDefault offers the candidates as complete words
+	completeDefault completionDirective = "default"
+	// completeNoSpace offers candidates that are the start of a word, such as .ext=
+	completeNoSpace completionDirective = "nospace"
+	// completeFiles lets the shell complete file names instead
+	completeFiles completionDirective = "files"
+	// completeDirs lets the shell complete directory names instead
+	completeDirs completionDirective = "dirs"
+)
+
+// candidate is one completion with an optional description
+type candidate struct {
+	value       string
+	description string
+}
+
+// completion is the answer to a completion request
+type completion struct {
+	candidates []candidate
+	directive  completionDirective
+}
+
+// completionShells lists the shells completion scripts are generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionFlags defines the flags of the completion command
+func completionFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("completion", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer completion bash|zsh|fish")
+		fmt.Fprintln(flags.Output())
+		fmt.Fprintln(flags.Output(), "Load the completion in the current shell with:")
+		fmt.Fprintln(flags.Output(), "  bash: source <(go-file-organizer completion bash)")
+		fmt.Fprintln(flags.Output(), "  zsh:  source <(go-file-organizer completion zsh)")
+		fmt.Fprintln(flags.Output(), "  fish: go-file-organizer completion fish | source")
+	}
+	return flags
+}
+
+// runCompletionCommand prints the completion script of a shell
+func runCompletionCommand(args []string) int {
+	flags := completionFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var script string
+	switch flags.Arg(0) {
+	case "bash":
+		script = bashCompletion
+	case "zsh":
+		script = zshCompletion
+	case "fish":
+		script = fishCompletion
+	default:
+		fmt.Fprintf(os.Stderr, "Unsupported shell: %s (expected one of: %s)\n", flags.Arg(0), strings.Join(completionShells, ", "))
+		return exitUsage
+	}
+	io.WriteString(os.Stdout, script)
+	return exitOK
+}
+
+// runCompleteCommand prints the candidates for the last of the given words,
+// one per line with a tab before any description, followed by the directive
+func runCompleteCommand(args []string) int {
+	result := complete(args)
+	for _, c := range result.candidates {
+		if c.description != "" {
+			fmt.Printf("%s\t%s\n", c.value, c.description)
+		} else {
+			fmt.Println(c.value)
+		}
+	}
+	fmt.Printf(":%s\n", result.directive)
+	return exitOK
+}
+
+// complete finds the candidates for the last word of a command line, given
+// without the program name. The last word is the one being completed and may
+// be empty.
+func complete(words []string) completion {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+	previous := words[:len(words)-1]
+
+	// The first word is a command, or an option of the legacy command line
+	if len(previous) == 0 && !strings.HasPrefix(current, "-") {
+		return filterCompletion(commandCompletion(commands()), current)
+	}
+	if len(previous) == 0 || strings.HasPrefix(previous[0], "-") {
+		return filterCompletion(completeArguments(command{name: "", flags: rootFlags}, previous, current), current)
+	}
+
+	cmd, found := findCommand(commands(), previous[0])
+	if !found || cmd.hidden {
+		return completion{directive: completeDefault}
+	}
+	previous = previous[1:]
+	if len(cmd.subcommands) > 0 {
+		if len(previous) == 0 {
+			return filterCompletion(commandCompletion(cmd.subcommands), current)
+		}
+		if cmd, found = findCommand(cmd.subcommands, previous[0]); !found {
+			return completion{directive: completeDefault}
+		}
+		previous = previous[1:]
+	}
+	return filterCompletion(completeArguments(cmd, previous, current), current)
+}
+
+// completeArguments completes a flag, the value of a flag or an argument of a command
+func completeArguments(cmd command, previous []string, current string) completion {
+	flags := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	if cmd.flags != nil {
+		flags = cmd.flags()
+	}
+	flags.SetOutput(io.Discard)
+
+	if strings.HasPrefix(current, "-") {
+		// The value of --flag=value, keeping the flag in the candidates
+		if name, value, found := strings.Cut(strings.TrimLeft(current, "-"), "="); found {
+			prefix := current[:len(current)-len(value)]
+			result := completeFlagValue(flags, name, previous, value)
+			for i := range result.candidates {
+				result.candidates[i].value = prefix + result.candidates[i].value
+			}
+			return result
+		}
+		return flagCompletion(flags)
+	}
+
+	// The value of a flag given as the previous word
+	if len(previous) > 0 {
+		last := previous[len(previous)-1]
+		if strings.HasPrefix(last, "-") && !strings.Contains(last, "=") {
+			if f := flags.Lookup(strings.TrimLeft(last, "-")); f != nil && !isBoolFlag(f) {
+				return completeFlagValue(flags, f.Name, previous, current)
+			}
+		}
+	}
+
+	switch cmd.name {
+	case "help":
+		return commandCompletion(commands())
+	case "completion":
+		return valueCompletion(completionShells...)
+	case "check-ignore", "validate":
+		return completion{directive: completeFiles}
+	default:
+		// Commands without arguments offer their flags
+		return flagCompletion(flags)
+	}
+}
+
+// completeFlagValue completes the value of a flag. The words typed so far
+// select the config file and profile that categories and profiles come from.
+func completeFlagValue(flags *flag.FlagSet, name string, previous []string, value string) completion {
+	switch name {
+	case "output":
+		return valueCompletion("text", "json", "ndjson")
+	case "format":
+		return valueCompletion("table", "json", "config")
+	case "unknown":
+		return valueCompletion(string(utils.UnknownLeave), string(utils.UnknownMove), string(utils.UnknownGroup))
+	case "log-level":
+		return valueCompletion("debug", "info", "warn", "error")
+	case "log-format":
+		return valueCompletion(string(utils.LogFormatText), string(utils.LogFormatJSON))
+	case "map":
+		return mappingCompletion(flags, previous, value)
+	case "skip-category":
+		// Complete the last category of a comma separated list
+		listed := value[:strings.LastIndex(value, ",")+1]
+		result := categoryCompletion(flags, previous)
+		for i := range result.candidates {
+			result.candidates[i].value = listed + result.candidates[i].value
+		}
+		return result
+	case "profile":
+		return profileCompletion(flags, previous)
+	case "run":
+		return runCompletion(flags, previous)
+	case "path", "journal-dir":
+		return completion{directive: completeDirs}
+	case "config", "ignore-file", "log-file", "plan-file", "script", "files-from":
+		return completion{directive: completeFiles}
+	default:
+		return completion{directive: completeDefault}
+	}
+}
+
+// mappingCompletion completes --map: first the extensions, then the categories
+// of the merged extension mapping
+func mappingCompletion(flags *flag.FlagSet, previous []string, value string) completion {
+	mapping, _ := completionMapping(flags, previous)
+	ext, _, hasCategory := strings.Cut(value, "=")
+	if hasCategory {
+		result := categoryCompletion(flags, previous)
+		for i := range result.candidates {
+			result.candidates[i].value = ext + "=" + result.candidates[i].value
+		}
+		return result
+	}
+
+	result := completion{directive: completeNoSpace}
+	for _, entry := range mapping.GetEntries() {
+		result.candidates = append(result.candidates, candidate{value: entry.Extension + "=", description: entry.Category})
+	}
+	return result
+}
+
+// categoryCompletion lists the categories of the merged extension mapping
+func categoryCompletion(flags *flag.FlagSet, previous []string) completion {
+	mapping, _ := completionMapping(flags, previous)
+	seen := make(map[string]bool)
+	var categories []string
+	for _, entry := range mapping.GetEntries() {
+		if !seen[entry.Category] {
+			seen[entry.Category] = true
+			categories = append(categories, entry.Category)
+		}
+	}
+	sort.Strings(categories)
+	return valueCompletion(categories...)
+}
+
+// profileCompletion lists the profiles of the config file
+func profileCompletion(flags *flag.FlagSet, previous []string) completion {
+	_, config := completionMapping(flags, previous)
+	var names []string
+	if config != nil {
+		for name := range config.Profiles {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return valueCompletion(names...)
+}
+
+// runCompletion lists the journaled runs that undo can take
+func runCompletion(flags *flag.FlagSet, previous []string) completion {
+	runs, err := organizer.ListJournals(journalDir(completionSettings(flags, previous)))
+	result := completion{directive: completeDefault}
+	if err != nil {
+		return result
+	}
+	for _, run := range runs {
+		description := fmt.Sprintf("%s, %d move(s) in %s", run.Started.Format("2006-01-02 15:04"), len(run.Moves), run.RootPath)
+		if run.Undone {
+			description += " (undone)"
+		}
+		result.candidates = append(result.candidates, candidate{value: run.ID, description: description})
+	}
+	return result
+}
+
+// completionSettings applies the flags among the words typed so far and
+// gathers the settings. Words that are not flags of the command are skipped.
+func completionSettings(flags *flag.FlagSet, words []string) *utils.Settings {
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+		f := flags.Lookup(name)
+		if f == nil {
+			continue
+		}
+		if !hasValue {
+			if isBoolFlag(f) {
+				value = "true"
+			} else if i+1 < len(words) {
+				i++
+				value = words[i]
+			} else {
+				continue
+			}
+		}
+		flags.Set(name, value)
+	}
+	return gatherSettings(flags, nil)
+}
+
+// completionMapping builds the extension mapping selected by the words typed
+// so far, falling back to the mapping without a profile if that fails
+func completionMapping(flags *flag.FlagSet, words []string) (*utils.ExtensionMapping, *utils.Config) {
+	settings := completionSettings(flags, words)
+	config, err := loadConfig(settings)
+	if err != nil {
+		config = nil
+	}
+
+	var overrides []string
+	if flags.Lookup("map") != nil {
+		overrides = mappingOverrides(flags)
+	}
+	mapping, _, err := buildExtensionMapping(config, settings.String("profile"), overrides, io.Discard)
+	if err != nil {
+		mapping, _, _ = buildExtensionMapping(config, "", nil, io.Discard)
+	}
+	return mapping, config
+}
+
+// commandCompletion lists the visible commands with their summaries
+func commandCompletion(list []command) completion {
+	result := completion{directive: completeDefault}
+	for _, cmd := range list {
+		if !cmd.hidden {
+			result.candidates = append(result.candidates, candidate{value: cmd.name, description: cmd.summary})
+		}
+	}
+	return result
+}
+
+// flagCompletion lists the flags of a flag set with their usage
+func flagCompletion(flags *flag.FlagSet) completion {
+	result := completion{directive: completeDefault}
+	flags.VisitAll(func(f *flag.Flag) {
+		name := "--" + f.Name
+		if len(f.Name) == 1 {
+			name = "-" + f.Name
+		}
+		result.candidates = append(result.candidates, candidate{value: name, description: f.Usage})
+	})
+	return result
+}
+
+// valueCompletion offers a fixed list of values
+func valueCompletion(values ...string) completion {
+	result := completion{directive: completeDefault}
+	for _, value := range values {
+		result.candidates = append(result.candidates, candidate{value: value})
+	}
+	return result
+}
+
+// filterCompletion keeps the candidates that start with the word being completed
+func filterCompletion(result completion, current string) completion {
+	kept := result.candidates[:0]
+	for _, c := range result.candidates {
+		if strings.HasPrefix(c.value, current) {
+			kept = append(kept, c)
+		}
+	}
+	result.candidates = kept
+	return result
+}
+
+// isBoolFlag reports whether a flag takes no value
+func isBoolFlag(f *flag.Flag) bool {
+	boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && boolFlag.IsBoolFlag()
+}
+
+// bashCompletion is the completion script for bash. It asks __complete for
+// the candidates, so it follows the commands and flags of the installed binary.
+const bashCompletion = `# bash completion for go-file-organizer
+#
+# Load it in the current shell:
+#   source <(go-file-organizer completion bash)
+# or install it for every shell:
+#   go-file-organizer completion bash > /etc/bash_completion.d/go-file-organizer
+
+_go_file_organizer() {
+    local line="${COMP_LINE:0:COMP_POINT}"
+    local -a words
+    read -r -a words <<< "$line"
+    if [[ $line == *[[:space:]] ]]; then
+        words+=("")
+    fi
+    local cur="${words[${#words[@]}-1]}"
+
+    # Bash splits words at = and : and only replaces the part after them
+    local part="${COMP_WORDS[COMP_CWORD]}"
+    if [[ $part == "=" || $part == ":" ]]; then
+        part=""
+    fi
+    local keep="${cur%"$part"}"
+
+    local output
+    output=$("${COMP_WORDS[0]}" __complete "${words[@]:1}" 2>/dev/null) || return 0
+    local -a lines
+    local entry
+    while IFS= read -r entry; do
+        lines+=("$entry")
+    done <<< "$output"
+    local directive="${lines[${#lines[@]}-1]#:}"
+    unset 'lines[${#lines[@]}-1]'
+
+    COMPREPLY=()
+    case $directive in
+    files | dirs)
+        compopt -o filenames 2>/dev/null
+        local type=-f
+        [[ $directive == dirs ]] && type=-d
+        while IFS= read -r entry; do
+            COMPREPLY+=("$entry")
+        done < <(compgen "$type" -- "$part")
+        return 0
+        ;;
+    nospace)
+        compopt -o nospace 2>/dev/null
+        ;;
+    esac
+
+    local value
+    for entry in "${lines[@]}"; do
+        value="${entry%%$'\t'*}"
+        if [[ $value == "$cur"* ]]; then
+            COMPREPLY+=("${value#"$keep"}")
+        fi
+    done
+}
+
+complete -F _go_file_organizer go-file-organizer
+`
+
+// zshCompletion is the completion script for zsh
+const zshCompletion = `#compdef go-file-organizer
+# zsh completion for go-file-organizer
+#
+# Load it in the current shell:
+#   source <(go-file-organizer completion zsh)
+# or install it as _go-file-organizer in a directory of $fpath.
+
+_go-file-organizer() {
+    local output
+    output=$("${words[1]}" __complete "${(@)words[2,CURRENT]}" 2>/dev/null) || return 1
+    local -a lines
+    lines=("${(@f)output}")
+    local directive="${lines[-1]#:}"
+    lines[-1]=()
+
+    # Complete the value of --flag=value on its own
+    local strip=""
+    if [[ $PREFIX == -*=* ]]; then
+        strip="${PREFIX%%=*}="
+        compset -P "$strip"
+    fi
+
+    case $directive in
+    files)
+        _files
+        return
+        ;;
+    dirs)
+        _files -/
+        return
+        ;;
+    esac
+
+    local -a described
+    local entry value
+    for entry in "${lines[@]}"; do
+        [[ -z $entry ]] && continue
+        value="${${entry%%$'\t'*}#$strip}"
+        if [[ $entry == *$'\t'* ]]; then
+            described+=("${value//:/\\:}:${entry#*$'\t'}")
+        else
+            described+=("${value//:/\\:}")
+        fi
+    done
+
+    if [[ $directive == nospace ]]; then
+        _describe -t values 'values' described -S ''
+    else
+        _describe -t values 'values' described
+    fi
+}
+
+if [[ $funcstack[1] == _go-file-organizer ]]; then
+    _go-file-organizer "$@"
+else
+    compdef _go-file-organizer go-file-organizer
+fi
+`
+
+// fishCompletion is the completion script for fish
+const fishCompletion = `# fish completion for go-file-organizer
+#
+# Load it in the current shell:
+#   go-file-organizer completion fish | source
+# or install it for every shell:
+#   go-file-organizer completion fish > ~/.config/fish/completions/go-file-organizer.fish
+
+function __go_file_organizer_complete
+    set -l words (commandline -opc)
+    set -l program $words[1]
+    set -e words[1]
+    set -l current (commandline -ct)
+    set -l lines ($program __complete $words "$current" 2>/dev/null)
+    or return
+
+    set -l directive (string replace -r '^:' '' -- $lines[-1])
+    set -e lines[-1]
+
+    # Complete the value of --flag=value on its own
+    set -l prefix (string match -r -- '^-[^=]*=' "$current")
+    set -l value (string replace -r -- '^-[^=]*=' '' "$current")
+
+    switch $directive
+        case files
+            for path in (__fish_complete_path "$value")
+                echo "$prefix$path"
+            end
+        case dirs
+            for path in (__fish_complete_directories "$value")
+                echo "$prefix$path"
+            end
+        case '*'
+            printf '%s\n' $lines
+    end
+end
+
+complete -c go-file-organizer -f -a '(__go_file_organizer_complete)'
+`
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigCandidates lists the config files looked up when none is given explicitly
+var defaultConfigCandidates = []string{"config/config.json", "config/config.yaml", "config/config.yml", "config/config.toml"}
+
+// runConfigCommand dispatches the "config" subcommands and returns the process exit code
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		printConfigUsage()
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "validate":
+		return runConfigValidate(args[1:])
+	case "show":
+		return runConfigShow(args[1:])
+	case "help", "--help", "-h":
+		printConfigUsage()
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown config command: %s\n", args[0])
+		printConfigUsage()
+		return exitUsage
+	}
+}
+
+// printConfigUsage prints usage instructions for the config subcommands
+func printConfigUsage() {
+	fmt.Println("Usage: go-file-organizer config <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  validate [--map .ext=Category] [file ...]  Strictly validate config files")
+	fmt.Println("  show [--format table|json|config]          Show the effective mappings and where they came from")
+	fmt.Println()
+	fmt.Println("Both commands accept --output json or ndjson for machine-readable output.")
+}
+
+// configValidateFlags defines the flags of the config validate command
+func configValidateFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	flags.String("config", "", "Config file to validate when no files are given (default: first of config/config.{json,yaml,yml,toml})")
+	flags.Var(&arrayFlags{}, "map", "Mapping override to check for shadowing (format: .ext=Category, can be used multiple times)")
+	addOutputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer config validate [--config file] [--map .ext=Category] [--output text|json|ndjson] [file ...]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runConfigValidate validates config files and reports every problem with its location.
+// Files are applied in the given order, followed by GFO_MAP and any --map overrides.
+func runConfigValidate(args []string) int {
+	flags := configValidateFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	settings := gatherSettings(flags, nil)
+	events := eventOutputFor(settings)
+	if events == nil {
+		return exitUsage
+	}
+
+	// Without arguments, validate the config file a run would load
+	configPaths := flags.Args()
+	if len(configPaths) == 0 {
+		configPath := configFilePath(settings)
+		if configPath == "" {
+			fmt.Fprintln(os.Stderr, "No config file found; pass the files to validate as arguments")
+			return exitUsage
+		}
+		configPaths = []string{configPath}
+	}
+
+	issues := utils.ValidateConfigFiles(organizer.GetDefaultExtensionCategories(), configPaths, os.Getenv(utils.EnvName("map")), mappingOverrides(flags))
+	for _, issue := range issues {
+		fmt.Fprintln(events.Text(), issue.String())
+		events.Emit(organizer.Event{Type: organizer.EventError, Path: issue.File, Line: issue.Line, Column: issue.Column, Reason: issue.Message})
+	}
+	events.SetSummary(validateSummary{Valid: len(issues) == 0, FilesChecked: len(configPaths), Issues: len(issues)})
+	events.Finish()
+
+	if len(issues) > 0 {
+		fmt.Fprintf(os.Stderr, "❌ Found %d problem(s) in configuration\n", len(issues))
+		return exitConfigError
+	}
+
+	fmt.Fprintf(events.Text(), "✅ Configuration is valid (%d file(s) checked)\n", len(configPaths))
+	return exitOK
+}
+
+// validateSummary is the summary of config validate in json and ndjson output
+type validateSummary struct {
+	Valid        bool `json:"valid"`
+	FilesChecked int  `json:"filesChecked"`
+	Issues       int  `json:"issues"`
+}
+
+// configShowFlags defines the flags of the config show command
+func configShowFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("config show", flag.ContinueOnError)
+	flags.String("config", "", "Config file to load (default: first of config/config.{json,yaml,yml,toml})")
+	flags.String("profile", "", "Named profile from the config file to apply")
+	flags.String("format", "table", "Output format: table, json or config")
+	addOutputFlag(flags)
+	flags.Var(&arrayFlags{}, "map", "Override extension mappings (format: .ext=Category, can be used multiple times)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer config show [--config file] [--profile name] [--map .ext=Category] [--format table|json|config] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runConfigShow prints the fully merged mapping table with the source of every entry
+func runConfigShow(args []string) int {
+	flags := configShowFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	settings := gatherSettings(flags, nil)
+	config, err := loadConfig(settings)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: could not load config file: %v\n", err)
+		return exitConfigError
+	}
+
+	// Keep stdout clean for the requested format
+	extensionMapping, _, err := buildExtensionMapping(config, settings.String("profile"), mappingOverrides(flags), io.Discard)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitConfigError
+	}
+
+	// --output json and ndjson take precedence over --format
+	mode, err := parseOutputMode(settings.String("output"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	format := settings.String("format")
+	switch mode {
+	case outputJSON:
+		format = "json"
+	case outputNDJSON:
+		format = "ndjson"
+	}
+
+	switch format {
+	case "table":
+		printMappingTable(os.Stdout, extensionMapping)
+	case "json":
+		err = printMappingJSON(os.Stdout, extensionMapping)
+	case "ndjson":
+		err = printMappingNDJSON(os.Stdout, extensionMapping)
+	case "config":
+		err = printMappingConfig(os.Stdout, extensionMapping, settings.String("profile"))
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown format '%s', expected table, json or config\n", format)
+		return exitUsage
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// printMappingTable prints the effective mappings and rules as aligned columns
+func printMappingTable(w io.Writer, extensionMapping *utils.ExtensionMapping) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "EXTENSION\tCATEGORY\tSOURCE\tORIGIN")
+	for _, entry := range extensionMapping.GetEntries() {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", entry.Extension, entry.Category, entry.Source, entry.Origin)
+	}
+	table.Flush()
+
+	if rules := extensionMapping.GetRules(); len(rules) > 0 {
+		fmt.Fprintln(w, "\nFilename rules (checked before extensions, in order):")
+		table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "PATTERN\tCATEGORY")
+		for _, rule := range rules {
+			fmt.Fprintf(table, "%s\t%s\n", rule.Pattern, rule.Category)
+		}
+		table.Flush()
+	}
+}
+
+// printMappingJSON prints the effective mappings and rules as a JSON document
+func printMappingJSON(w io.Writer, extensionMapping *utils.ExtensionMapping) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Mappings []utils.MappingEntry `json:"mappings"`
+		Rules    []utils.Rule         `json:"rules"`
+	}{
+		Mappings: extensionMapping.GetEntries(),
+		Rules:    extensionMapping.GetRules(),
+	})
+}
+
+// printMappingNDJSON prints one JSON object per line for every mapping and rule,
+// tagged with a "type" of "mapping" or "rule"
+func printMappingNDJSON(w io.Writer, extensionMapping *utils.ExtensionMapping) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range extensionMapping.GetEntries() {
+		if err := encoder.Encode(struct {
+			Type string `json:"type"`
+			utils.MappingEntry
+		}{"mapping", entry}); err != nil {
+			return err
+		}
+	}
+	for _, rule := range extensionMapping.GetRules() {
+		if err := encoder.Encode(struct {
+			Type string `json:"type"`
+			utils.Rule
+		}{"rule", rule}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printMappingConfig prints the effective mappings as a ready-to-edit YAML config file.
+// Each mapping is annotated with its source, and rules are kept in the selected profile.
+func printMappingConfig(w io.Writer, extensionMapping *utils.ExtensionMapping, profileName string) error {
+	scalar := func(value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+
+	mappings := &yaml.Node{Kind: yaml.MappingNode}
+	for _, entry := range extensionMapping.GetEntries() {
+		value := scalar(entry.Category)
+		value.LineComment = entry.Source
+		if entry.Origin != "" {
+			value.LineComment += ": " + entry.Origin
+		}
+		mappings.Content = append(mappings.Content, scalar(entry.Extension), value)
+	}
+
+	root := &yaml.Node{
+		Kind:        yaml.MappingNode,
+		HeadComment: "Effective configuration generated by go-file-organizer config show",
+		Content:     []*yaml.Node{scalar("customMappings"), mappings},
+	}
+
+	if rules := extensionMapping.GetRules(); len(rules) > 0 {
+		ruleList := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, rule := range rules {
+			ruleList.Content = append(ruleList.Content, &yaml.Node{
+				Kind:    yaml.MappingNode,
+				Content: []*yaml.Node{scalar("pattern"), scalar(rule.Pattern), scalar("category"), scalar(rule.Category)},
+			})
+		}
+
+		profile := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("rules"), ruleList}}
+		root.Content = append(root.Content,
+			scalar("profiles"),
+			&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar(profileName), profile}},
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"os"
+	"path/filepath"
+)
+
+// initSummary is the summary of the init command in json and ndjson output
+type initSummary struct {
+	FilesScanned      int      `json:"filesScanned"`
+	UnknownExtensions []string `json:"unknownExtensions"`
+	ConfigFile        string   `json:"configFile,omitempty"`
+	IgnoreFile        string   `json:"ignoreFile,omitempty"`
+}
+
+// initFlags defines the flags of the init command
+func initFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.String("path", ".", "Directory to scan and write the config and ignore file into")
+	flags.Bool("force", false, "Overwrite existing files")
+	addOutputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer init --path <directory> [--force] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runInitCommand scaffolds a commented config and ignore file in a target directory
+func runInitCommand(args []string) int {
+	flags := initFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	settings := gatherSettings(flags, nil)
+	events := eventOutputFor(settings)
+	if events == nil {
+		return exitUsage
+	}
+	out := events.Text()
+	path := settings.String("path")
+
+	// fail reports an error and writes the structured output before exiting
+	fail := func(path, format string, args ...interface{}) int {
+		message := fmt.Sprintf(format, args...)
+		fmt.Fprintf(out, "Error: %s\n", message)
+		events.Emit(organizer.Event{Type: organizer.EventError, Path: path, Reason: message})
+		events.Finish()
+		return exitError
+	}
+
+	fmt.Fprintf(out, "🔍 Scanning %s...\n", path)
+	scaffold, err := organizer.GenerateScaffold(context.Background(), path)
+	if err != nil {
+		return fail(path, "%v", err)
+	}
+
+	configPath := filepath.Join(path, organizer.ScaffoldConfigName)
+	ignorePath := filepath.Join(path, organizer.ScaffoldIgnoreName)
+	summary := initSummary{FilesScanned: scaffold.FilesScanned, UnknownExtensions: scaffold.UnknownExtensions}
+	if summary.UnknownExtensions == nil {
+		summary.UnknownExtensions = []string{}
+	}
+	events.SetSummary(summary)
+
+	// Refuse to clobber existing files unless asked to
+	if !boolFlag(flags, "force") {
+		for _, target := range []string{configPath, ignorePath} {
+			if _, err := os.Stat(target); err == nil {
+				return fail(target, "%s already exists (use --force to overwrite)", target)
+			}
+		}
+	}
+
+	if err := os.WriteFile(configPath, []byte(scaffold.Config), 0644); err != nil {
+		return fail(configPath, "writing config: %v", err)
+	}
+	events.Emit(organizer.Event{Type: organizer.EventCreated, Path: configPath})
+	summary.ConfigFile = configPath
+
+	if err := os.WriteFile(ignorePath, []byte(scaffold.Ignore), 0644); err != nil {
+		events.SetSummary(summary)
+		return fail(ignorePath, "writing ignore file: %v", err)
+	}
+	events.Emit(organizer.Event{Type: organizer.EventCreated, Path: ignorePath})
+	summary.IgnoreFile = ignorePath
+	events.SetSummary(summary)
+
+	fmt.Fprintf(out, "✅ Scanned %d files, found %d unknown extension(s)\n", scaffold.FilesScanned, len(scaffold.UnknownExtensions))
+	fmt.Fprintf(out, "📄 Wrote %s\n", configPath)
+	fmt.Fprintf(out, "🚫 Wrote %s\n", ignorePath)
+	fmt.Fprintln(out, "\nReview the proposed mappings, then preview with:")
+	fmt.Fprintf(out, "  go-file-organizer --config %s --dry-run\n", configPath)
+	events.Finish()
+	return exitOK
+}
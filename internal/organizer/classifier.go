@@ -0,0 +1,140 @@
+package organizer
+
+import (
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// HeaderSize is the number of leading bytes of a file offered to classifiers
+const HeaderSize = 512
+
+// Classification is the category a classifier found for a file, along with
+// anything else it learned about it, e.g. the number of an in-house report
+type Classification struct {
+	Category string
+	Metadata map[string]string
+}
+
+// Classifier decides the category of a file. Classifiers are consulted in a
+// chain and the first one that recognizes a file decides its category.
+type Classifier interface {
+	// Classify returns the category of a file and its metadata, or false if it
+	// does not recognize the file
+	Classify(file *Candidate) (Classification, bool)
+}
+
+// ClassifierFunc adapts a function to the Classifier interface
+type ClassifierFunc func(file *Candidate) (Classification, bool)
+
+// Classify implements Classifier
+func (f ClassifierFunc) Classify(file *Candidate) (Classification, bool) {
+	return f(file)
+}
+
+// Candidate is a file to classify. Its header is only read when a classifier
+// asks for it, and at most once for the whole chain.
+type Candidate struct {
+	// Path is the path of the file, inside the root being organized
+	Path string
+	// Info describes the file
+	Info fs.FileInfo
+
+	fsys       FS
+	header     []byte
+	headerRead bool
+}
+
+// NewCandidate returns a candidate for the file at path, whose header is read
+// from fsys (nil for the local filesystem)
+func NewCandidate(fsys FS, path string, info fs.FileInfo) *Candidate {
+	if fsys == nil {
+		fsys = OSFS{}
+	}
+	return &Candidate{Path: path, Info: info, fsys: fsys}
+}
+
+// Header returns up to HeaderSize leading bytes of the file, or nil if it is
+// empty or cannot be read
+func (c *Candidate) Header() []byte {
+	if c.headerRead {
+		return c.header
+	}
+	c.headerRead = true
+
+	file, err := c.fsys.Open(c.Path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(file, header)
+	if n > 0 && (err == nil || err == io.ErrUnexpectedEOF) {
+		c.header = header[:n]
+	}
+	return c.header
+}
+
+// ExtensionClassifier recognizes files by their extension and the filename
+// rules of the mapping; it is the default classifier
+type ExtensionClassifier struct {
+	// Mapping maps extensions to categories (nil for the default mappings)
+	Mapping *utils.ExtensionMapping
+}
+
+// Classify implements Classifier
+func (c ExtensionClassifier) Classify(file *Candidate) (Classification, bool) {
+	name := filepath.Base(file.Path)
+	if c.Mapping != nil {
+		category, exists := c.Mapping.GetCategory(name)
+		return Classification{Category: category}, exists
+	}
+	category, exists := extensionCategories[strings.ToLower(filepath.Ext(name))]
+	return Classification{Category: category}, exists
+}
+
+// ContentClassifier recognizes files from the MIME type of their header, as
+// detected by net/http; it is added to the chain by Options.SniffContent
+type ContentClassifier struct{}
+
+// Classify implements Classifier
+func (ContentClassifier) Classify(file *Candidate) (Classification, bool) {
+	category, ok := sniffHeader(file.Header())
+	return Classification{Category: category}, ok
+}
+
+// classifiers returns the chain of a run: Classifiers, then the extension
+// lookup and, with SniffContent, the content of the file
+func (o Options) classifiers() []Classifier {
+	chain := append([]Classifier{}, o.Classifiers...)
+	chain = append(chain, ExtensionClassifier{Mapping: o.ExtensionMapping})
+	if o.SniffContent {
+		chain = append(chain, ContentClassifier{})
+	}
+	return chain
+}
+
+// classify returns the classification of a file by the first classifier of
+// the chain that recognizes it, with "No Extension" and "Unknown" for files
+// none recognizes. A category that is not a valid folder name is an error.
+func classify(chain []Classifier, file *Candidate) (Classification, error) {
+	for _, classifier := range chain {
+		classification, ok := classifier.Classify(file)
+		if !ok {
+			continue
+		}
+		if err := utils.ValidateCategory(classification.Category); err != nil {
+			return Classification{}, fmt.Errorf("invalid category '%s' from classifier %T: %v", classification.Category, classifier, err)
+		}
+		return classification, nil
+	}
+
+	// Handle files with no extension or unknown extensions
+	if filepath.Ext(file.Path) == "" {
+		return Classification{Category: "No Extension"}, nil
+	}
+	return Classification{Category: "Unknown"}, nil
+}
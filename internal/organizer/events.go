@@ -0,0 +1,62 @@
+package organizer
+
+import (
+	"go-file-organizer/internal/utils"
+	"time"
+)
+
+// EventType identifies what happened to a file during a run
+type EventType string
+
+const (
+	// EventPlanned is a move that a dry run would perform
+	EventPlanned EventType = "planned"
+	// EventMoved is a file that was moved
+	EventMoved EventType = "moved"
+	// EventSkipped is a file left in place
+	EventSkipped EventType = "skipped"
+	// EventError is a file or folder that could not be processed
+	EventError EventType = "error"
+	// EventCreated is a folder or file that was created
+	EventCreated EventType = "created"
+	// EventSummary carries the summary at the end of a run
+	EventSummary EventType = "summary"
+)
+
+// Event is a structured record of a single action, for machine-readable output
+type Event struct {
+	Type        EventType         `json:"type"`
+	Time        time.Time         `json:"time"`
+	Path        string            `json:"path,omitempty"`
+	Destination string            `json:"destination,omitempty"`
+	Category    string            `json:"category,omitempty"`
+	Reason      string            `json:"reason,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Line        int               `json:"line,omitempty"`
+	Column      int               `json:"column,omitempty"`
+	Summary     *utils.Summary    `json:"summary,omitempty"`
+}
+
+// emit sends an event to the OnEvent callback and the observer, if set
+func (o Options) emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if o.OnEvent != nil {
+		o.OnEvent(event)
+	}
+	if o.Observer == nil {
+		return
+	}
+	switch event.Type {
+	case EventMoved, EventPlanned:
+		o.Observer.Moved(event)
+	case EventError:
+		o.Observer.Failed(event)
+	case EventSkipped:
+		// Files left in place are decisions; the destination is not used
+		o.Observer.Decided(Decision{Path: event.Path, Category: event.Category, Reason: event.Reason})
+	case EventSummary:
+		o.Observer.Finished(event.Summary)
+	}
+}
@@ -0,0 +1,171 @@
+package organizer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+)
+
+// FS is the filesystem a run works on: the tree it scans and the folders and
+// moves it makes. OSFS is the local filesystem and MemFS an in-memory tree,
+// e.g. for tests; other backends can be plugged in through Options.FS.
+// Journals, plans and config files always live on the local filesystem.
+type FS interface {
+	// Stat describes a path, following symbolic links
+	Stat(path string) (fs.FileInfo, error)
+	// Lstat describes a path without following symbolic links
+	Lstat(path string) (fs.FileInfo, error)
+	// Walk visits the tree rooted at root in lexical order, like filepath.Walk
+	Walk(root string, fn filepath.WalkFunc) error
+	// ReadDir lists a directory sorted by name
+	ReadDir(path string) ([]fs.DirEntry, error)
+	// Open opens a file for reading
+	Open(path string) (fs.File, error)
+	// MkdirAll creates a directory along with any missing parents
+	MkdirAll(path string, perm fs.FileMode) error
+	// Rename moves a file, replacing the destination if it exists
+	Rename(oldPath, newPath string) error
+	// Copy copies a file with its mode and modification time
+	Copy(source, destination string) error
+	// Remove removes a file or an empty directory
+	Remove(path string) error
+}
+
+// OSFS is the local filesystem
+type OSFS struct{}
+
+// Stat implements FS
+func (OSFS) Stat(path string) (fs.FileInfo, error) { return os.Stat(path) }
+
+// Lstat implements FS
+func (OSFS) Lstat(path string) (fs.FileInfo, error) { return os.Lstat(path) }
+
+// Walk implements FS
+func (OSFS) Walk(root string, fn filepath.WalkFunc) error { return filepath.Walk(root, fn) }
+
+// ReadDir implements FS
+func (OSFS) ReadDir(path string) ([]fs.DirEntry, error) { return os.ReadDir(path) }
+
+// Open implements FS
+func (OSFS) Open(path string) (fs.File, error) { return os.Open(path) }
+
+// MkdirAll implements FS
+func (OSFS) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+
+// Rename implements FS
+func (OSFS) Rename(oldPath, newPath string) error { return os.Rename(oldPath, newPath) }
+
+// Remove implements FS
+func (OSFS) Remove(path string) error { return os.Remove(path) }
+
+// Copy implements FS. A partly written destination is removed.
+func (OSFS) Copy(source, destination string) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return &fs.PathError{Op: "copy", Path: source, Err: errors.New("not a regular file")}
+	}
+
+	dst, err := os.OpenFile(destination, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(destination)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(destination)
+		return err
+	}
+	return os.Chtimes(destination, info.ModTime(), info.ModTime())
+}
+
+// filesystem returns the filesystem of a run
+func (o Options) filesystem() FS {
+	if o.FS == nil {
+		return OSFS{}
+	}
+	return o.FS
+}
+
+// renameOrCopy moves a file with Rename, or by copying and removing it when
+// the destination is on another device
+func renameOrCopy(fsys FS, source, destination string) error {
+	err := fsys.Rename(source, destination)
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	if err := fsys.Copy(source, destination); err != nil {
+		return fmt.Errorf("failed to copy across devices: %v", err)
+	}
+	if err := fsys.Remove(source); err != nil {
+		return fmt.Errorf("failed to remove %s after copying it: %v", source, err)
+	}
+	return nil
+}
+
+// walk implements filepath.Walk on top of Lstat and ReadDir, for filesystems
+// that have no walk of their own
+func walk(fsys FS, root string, fn filepath.WalkFunc) error {
+	info, err := fsys.Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(fsys, root, info, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkDir visits path and, for a directory, its entries
+func walkDir(fsys FS, path string, info fs.FileInfo, fn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+
+	entries, readErr := fsys.ReadDir(path)
+	err := fn(path, info, readErr)
+	// A directory that cannot be read is reported with the error and not entered
+	if readErr != nil || err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := filepath.Join(path, name)
+		childInfo, err := fsys.Lstat(child)
+		if err != nil {
+			if err := fn(child, childInfo, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+		err = walkDir(fsys, child, childInfo, fn)
+		if err != nil {
+			if !childInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+package organizer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// journalExt is the extension of journal files
+const journalExt = ".jsonl"
+
+// undoneExt is appended to the name of a journal whose moves have all been undone
+const undoneExt = ".undone"
+
+// journalTimeLayout is the timestamp that names a journal and identifies its run
+const journalTimeLayout = "20060102-150405.000"
+
+// DefaultJournalDir returns where journals are written by default, the journal
+// folder of the state directory
+func DefaultJournalDir() string {
+	return filepath.Join(utils.DefaultStateDir(), "journal")
+}
+
+// JournalEntry is a move recorded in a journal
+type JournalEntry struct {
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	Category    string    `json:"category,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// journalHeader is the first line of a journal file
+type journalHeader struct {
+	RootPath string    `json:"rootPath"`
+	Started  time.Time `json:"started"`
+}
+
+// Journal records the moves of a run, one JSON line per move, so that the run
+// can be undone. A nil journal records nothing. It is safe for concurrent use.
+type Journal struct {
+	mu    sync.Mutex
+	path  string
+	file  *os.File
+	moves int
+	err   error
+}
+
+// CreateJournal starts the journal of a run on rootPath in dir, creating the
+// directory if needed
+func CreateJournal(dir, rootPath string) (*Journal, error) {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve root path: %v", err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %v", err)
+	}
+
+	// Runs are identified by their start time; bump it until the name is free
+	started := time.Now()
+	var file *os.File
+	for {
+		path := filepath.Join(dir, started.Format(journalTimeLayout)+journalExt)
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create journal: %v", err)
+		}
+		started = started.Add(time.Millisecond)
+	}
+
+	j := &Journal{path: file.Name(), file: file}
+	if err := json.NewEncoder(file).Encode(journalHeader{RootPath: absRoot, Started: started}); err != nil {
+		file.Close()
+		os.Remove(j.path)
+		return nil, fmt.Errorf("failed to write journal: %v", err)
+	}
+	return j, nil
+}
+
+// Path returns the journal file
+func (j *Journal) Path() string {
+	if j == nil {
+		return ""
+	}
+	return j.path
+}
+
+// Moves returns the number of moves recorded so far
+func (j *Journal) Moves() int {
+	if j == nil {
+		return 0
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.moves
+}
+
+// Record appends a completed move to the journal. Write errors are kept and
+// returned by Close so that a run is not interrupted by its journal.
+func (j *Journal) Record(source, destination, category string) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil || j.err != nil {
+		return
+	}
+	// Undo may run from another directory
+	if abs, err := filepath.Abs(source); err == nil {
+		source = abs
+	}
+	if abs, err := filepath.Abs(destination); err == nil {
+		destination = abs
+	}
+
+	entry := JournalEntry{Source: source, Destination: destination, Category: category, Time: time.Now()}
+	if err := json.NewEncoder(j.file).Encode(entry); err != nil {
+		j.err = fmt.Errorf("failed to write journal: %v", err)
+		return
+	}
+	j.moves++
+}
+
+// Close closes the journal. A journal without moves is removed, since there
+// is nothing to undo.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return j.err
+	}
+	closeErr := j.file.Close()
+	j.file = nil
+	if j.moves == 0 {
+		os.Remove(j.path)
+	}
+	if j.err != nil {
+		return j.err
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write journal: %v", closeErr)
+	}
+	return nil
+}
+
+// Run is a journaled run whose moves can be undone
+type Run struct {
+	// ID identifies the run; it is the start time in the journal's name
+	ID       string         `json:"id"`
+	Path     string         `json:"path"`
+	RootPath string         `json:"rootPath"`
+	Started  time.Time      `json:"started"`
+	Undone   bool           `json:"undone"`
+	Moves    []JournalEntry `json:"moves"`
+}
+
+// LoadJournal reads a journal file
+func LoadJournal(path string) (*Run, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %v", err)
+	}
+	defer file.Close()
+
+	name := filepath.Base(path)
+	undone := strings.HasSuffix(name, undoneExt)
+	run := &Run{
+		ID:     strings.TrimSuffix(strings.TrimSuffix(name, undoneExt), journalExt),
+		Path:   path,
+		Undone: undone,
+		Moves:  []JournalEntry{},
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if line == 1 {
+			var header journalHeader
+			if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.RootPath == "" {
+				return nil, fmt.Errorf("invalid journal %s: missing header", path)
+			}
+			run.RootPath = header.RootPath
+			run.Started = header.Started
+			continue
+		}
+
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal %s: line %d: %v", path, line, err)
+		}
+		if entry.Source == "" || entry.Destination == "" {
+			return nil, fmt.Errorf("invalid journal %s: line %d needs a source and a destination", path, line)
+		}
+		run.Moves = append(run.Moves, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %v", err)
+	}
+	if line == 0 {
+		return nil, fmt.Errorf("invalid journal %s: file is empty", path)
+	}
+
+	return run, nil
+}
+
+// ListJournals returns the journaled runs in dir, newest first. A missing
+// directory has no runs.
+func ListJournals(dir string) ([]*Run, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []*Run{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list journals: %v", err)
+	}
+
+	runs := []*Run{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, journalExt) || strings.HasSuffix(name, journalExt+undoneExt)) {
+			continue
+		}
+		run, err := LoadJournal(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].ID > runs[j].ID
+	})
+	return runs, nil
+}
+
+// FindJournal returns the run with the given ID, or the most recent run that
+// has not been undone if id is empty
+func FindJournal(dir, id string) (*Run, error) {
+	runs, err := ListJournals(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if id == "" && !run.Undone {
+			return run, nil
+		}
+		if id != "" && run.ID == id {
+			return run, nil
+		}
+	}
+	if id == "" {
+		return nil, fmt.Errorf("no run to undo in %s", dir)
+	}
+	return nil, fmt.Errorf("no run with ID %s in %s", id, dir)
+}
+
+// Undo moves the files of a journaled run back to where they came from, most
+// recent move first, and removes the category folders left empty. Moves that
+// cannot be undone, because the file is gone or its original location is
+// taken, are reported as failures and kept in the journal so that the undo can
+// be retried; once every move is undone the journal is marked as undone.
+// Once ctx is done no further move is undone: the journal keeps the moves not
+// yet undone and the error wraps ErrInterrupted.
+func Undo(ctx context.Context, run *Run, opts Options) (*utils.Summary, error) {
+	start := time.Now()
+	if run.Undone {
+		return nil, fmt.Errorf("run %s has already been undone", run.ID)
+	}
+	// Every record of the run shares one session
+	opts.Logger = utils.AsLogger(opts.Logger)
+	opts.Observer = opts.observer()
+	logger := utils.AsLogger(opts.Logger)
+	fsys := opts.filesystem()
+	isDryRun := opts.DryRun
+	out := opts.output()
+	summary := utils.NewSummary()
+
+	if opts.Observer != nil {
+		opts.Observer.Started(len(run.Moves))
+	}
+
+	var remaining []JournalEntry
+	var stopErr error
+	folders := make(map[string]bool)
+	for i := len(run.Moves) - 1; i >= 0; i-- {
+		if stopErr = interrupted(ctx); stopErr != nil {
+			// Keep the moves not reached yet, in their original order
+			remaining = append(append([]JournalEntry(nil), run.Moves[:i+1]...), remaining...)
+			break
+		}
+		entry := run.Moves[i]
+		summary.RecordScanned(entry.Destination)
+
+		size, err := undoMove(fsys, entry, isDryRun)
+		if err != nil {
+			summary.RecordFailure("Undo", entry.Destination, err)
+			opts.emit(Event{Type: EventError, Path: entry.Destination, Destination: entry.Source, Category: entry.Category, Reason: err.Error()})
+			logger.LogError("Undo", entry.Destination, err)
+			fmt.Fprintf(out, "  [ERROR] Failed to restore %s: %v\n", entry.Destination, err)
+			remaining = append([]JournalEntry{entry}, remaining...)
+			continue
+		}
+
+		fields := utils.LogFields{Op: "undo", Src: entry.Destination, Dst: entry.Source, Category: entry.Category, Bytes: size}
+		if isDryRun {
+			logger.Info(fmt.Sprintf("[DRY-RUN] Would restore: %s -> %s", entry.Destination, entry.Source), fields)
+			opts.emit(Event{Type: EventPlanned, Path: entry.Destination, Destination: entry.Source, Category: entry.Category})
+			fmt.Fprintf(out, "  [DRY-RUN] Would restore: %s -> %s\n", entry.Destination, entry.Source)
+		} else {
+			logger.Info(fmt.Sprintf("[UNDO] Restored: %s -> %s", entry.Destination, entry.Source), fields)
+			opts.emit(Event{Type: EventMoved, Path: entry.Destination, Destination: entry.Source, Category: entry.Category})
+			fmt.Fprintf(out, "  [RESTORED] %s -> %s\n", entry.Destination, entry.Source)
+			folders[filepath.Dir(entry.Destination)] = true
+		}
+		summary.RecordMove(entry.Category, size)
+	}
+
+	if !isDryRun {
+		removeEmptyFolders(fsys, folders, run.RootPath, logger)
+		if err := updateJournal(run, remaining); err != nil {
+			return summary, err
+		}
+	}
+
+	summary.Duration = time.Since(start)
+	logger.LogSummary(*summary)
+	opts.emit(Event{Type: EventSummary, Summary: summary})
+	return summary, stopErr
+}
+
+// undoMove moves a file back to its source and returns its size. In a dry run
+// the move is only checked.
+func undoMove(fsys FS, entry JournalEntry, isDryRun bool) (int64, error) {
+	info, err := fsys.Stat(entry.Destination)
+	if err != nil {
+		return 0, fmt.Errorf("moved file is no longer available: %v", err)
+	}
+	if _, err := fsys.Lstat(entry.Source); err == nil {
+		return 0, fmt.Errorf("original location is taken: %s", entry.Source)
+	}
+	if isDryRun {
+		return info.Size(), nil
+	}
+
+	if err := fsys.MkdirAll(filepath.Dir(entry.Source), 0755); err != nil {
+		return 0, fmt.Errorf("failed to recreate original directory: %v", err)
+	}
+	if err := renameOrCopy(fsys, entry.Destination, entry.Source); err != nil {
+		return 0, fmt.Errorf("failed to move file back: %v", err)
+	}
+	return info.Size(), nil
+}
+
+// removeEmptyFolders removes the given category folders if nothing is left in
+// them, then their parents up to the root as they become empty. The root itself
+// and the parents of folders outside it are never removed.
+func removeEmptyFolders(fsys FS, folders map[string]bool, rootPath string, logger *utils.Logger) {
+	paths := make([]string, 0, len(folders))
+	for folder := range folders {
+		paths = append(paths, folder)
+	}
+	// Deepest first, so that nested folders empty their parents
+	sort.Slice(paths, func(i, j int) bool {
+		return len(paths[i]) > len(paths[j])
+	})
+
+	for _, folder := range paths {
+		for folder != rootPath {
+			entries, err := fsys.ReadDir(folder)
+			if err != nil || len(entries) > 0 {
+				break
+			}
+			if err := fsys.Remove(folder); err != nil {
+				break
+			}
+			logger.Info("[UNDO] Removed empty folder: "+folder, utils.LogFields{Op: "undo", Src: folder})
+
+			// e.g. Other once Other/.xyz is gone
+			folder = filepath.Dir(folder)
+			if rel, err := filepath.Rel(rootPath, folder); err != nil || strings.HasPrefix(rel, "..") {
+				break
+			}
+		}
+	}
+}
+
+// updateJournal marks a journal as undone, or rewrites it with the moves that
+// could not be undone
+func updateJournal(run *Run, remaining []JournalEntry) error {
+	if len(remaining) == 0 {
+		if err := os.Rename(run.Path, run.Path+undoneExt); err != nil {
+			return fmt.Errorf("failed to mark journal as undone: %v", err)
+		}
+		run.Path += undoneExt
+		run.Undone = true
+		return nil
+	}
+
+	var data strings.Builder
+	encoder := json.NewEncoder(&data)
+	encoder.Encode(journalHeader{RootPath: run.RootPath, Started: run.Started})
+	for _, entry := range remaining {
+		encoder.Encode(entry)
+	}
+	if err := os.WriteFile(run.Path, []byte(data.String()), 0644); err != nil {
+		return fmt.Errorf("failed to update journal: %v", err)
+	}
+	run.Moves = remaining
+	return nil
+}
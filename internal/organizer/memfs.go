@@ -0,0 +1,277 @@
+package organizer
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory FS, so that runs can be tested or previewed without
+// touching the disk. Paths are cleaned with filepath.Clean; parent directories
+// of the files written with WriteFile are created as needed. It is safe for
+// concurrent use.
+type MemFS struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode
+}
+
+// memNode is a file or directory of a MemFS
+type memNode struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// NewMemFS returns an empty in-memory filesystem
+func NewMemFS() *MemFS {
+	return &MemFS{nodes: make(map[string]*memNode)}
+}
+
+// WriteFile creates or replaces a file along with its missing parent directories
+func (m *MemFS) WriteFile(path string, data []byte, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	if node, exists := m.nodes[path]; exists && node.mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: path, Err: errIsDir}
+	}
+	if err := m.mkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	m.nodes[path] = &memNode{data: bytes.Clone(data), mode: perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// ReadFile returns the content of a file
+func (m *MemFS) ReadFile(path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, err := m.file(path, "read")
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(node.data), nil
+}
+
+// Stat implements FS; MemFS has no symbolic links
+func (m *MemFS) Stat(path string) (fs.FileInfo, error) {
+	return m.Lstat(path)
+}
+
+// Lstat implements FS
+func (m *MemFS) Lstat(path string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	node, exists := m.nodes[path]
+	if !exists {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+	return node.info(filepath.Base(path)), nil
+}
+
+// Walk implements FS
+func (m *MemFS) Walk(root string, fn filepath.WalkFunc) error {
+	return walk(m, filepath.Clean(root), fn)
+}
+
+// ReadDir implements FS
+func (m *MemFS) ReadDir(path string) ([]fs.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	node, exists := m.nodes[path]
+	if !exists {
+		return nil, &fs.PathError{Op: "readdir", Path: path, Err: fs.ErrNotExist}
+	}
+	if !node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: path, Err: errNotDir}
+	}
+
+	var entries []fs.DirEntry
+	for _, child := range m.children(path) {
+		entries = append(entries, fs.FileInfoToDirEntry(m.nodes[child].info(filepath.Base(child))))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// Open implements FS
+func (m *MemFS) Open(path string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, err := m.file(path, "open")
+	if err != nil {
+		return nil, err
+	}
+	return &memFile{Reader: bytes.NewReader(node.data), stat: node.info(filepath.Base(path))}, nil
+}
+
+// MkdirAll implements FS
+func (m *MemFS) MkdirAll(path string, perm fs.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mkdirAll(filepath.Clean(path), perm)
+}
+
+// Rename implements FS. Directories are moved with everything in them.
+func (m *MemFS) Rename(oldPath, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldPath, newPath = filepath.Clean(oldPath), filepath.Clean(newPath)
+	node, exists := m.nodes[oldPath]
+	if !exists {
+		return &fs.PathError{Op: "rename", Path: oldPath, Err: fs.ErrNotExist}
+	}
+	if oldPath == newPath {
+		return nil
+	}
+	if parent, exists := m.nodes[filepath.Dir(newPath)]; !exists || !parent.mode.IsDir() {
+		return &fs.PathError{Op: "rename", Path: newPath, Err: fs.ErrNotExist}
+	}
+	if target, exists := m.nodes[newPath]; exists && (target.mode.IsDir() || node.mode.IsDir()) {
+		return &fs.PathError{Op: "rename", Path: newPath, Err: fs.ErrExist}
+	}
+
+	if node.mode.IsDir() {
+		if strings.HasPrefix(newPath, oldPath+string(filepath.Separator)) {
+			return &fs.PathError{Op: "rename", Path: newPath, Err: fs.ErrInvalid}
+		}
+		for _, descendant := range m.descendants(oldPath) {
+			m.nodes[newPath+strings.TrimPrefix(descendant, oldPath)] = m.nodes[descendant]
+			delete(m.nodes, descendant)
+		}
+	}
+	m.nodes[newPath] = node
+	delete(m.nodes, oldPath)
+	return nil
+}
+
+// Copy implements FS
+func (m *MemFS) Copy(source, destination string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	node, err := m.file(source, "copy")
+	if err != nil {
+		return err
+	}
+	destination = filepath.Clean(destination)
+	if parent, exists := m.nodes[filepath.Dir(destination)]; !exists || !parent.mode.IsDir() {
+		return &fs.PathError{Op: "copy", Path: destination, Err: fs.ErrNotExist}
+	}
+	if target, exists := m.nodes[destination]; exists && target.mode.IsDir() {
+		return &fs.PathError{Op: "copy", Path: destination, Err: errIsDir}
+	}
+	m.nodes[destination] = &memNode{data: bytes.Clone(node.data), mode: node.mode, modTime: node.modTime}
+	return nil
+}
+
+// Remove implements FS
+func (m *MemFS) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	node, exists := m.nodes[path]
+	if !exists {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	if node.mode.IsDir() && len(m.children(path)) > 0 {
+		return &fs.PathError{Op: "remove", Path: path, Err: errNotEmpty}
+	}
+	delete(m.nodes, path)
+	return nil
+}
+
+// file returns the regular file at path
+func (m *MemFS) file(path, op string) (*memNode, error) {
+	path = filepath.Clean(path)
+	node, exists := m.nodes[path]
+	if !exists {
+		return nil, &fs.PathError{Op: op, Path: path, Err: fs.ErrNotExist}
+	}
+	if node.mode.IsDir() {
+		return nil, &fs.PathError{Op: op, Path: path, Err: errIsDir}
+	}
+	return node, nil
+}
+
+// mkdirAll creates a directory and its missing parents; the lock must be held
+func (m *MemFS) mkdirAll(path string, perm fs.FileMode) error {
+	if node, exists := m.nodes[path]; exists {
+		if !node.mode.IsDir() {
+			return &fs.PathError{Op: "mkdir", Path: path, Err: errNotDir}
+		}
+		return nil
+	}
+	if parent := filepath.Dir(path); parent != path {
+		if err := m.mkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	m.nodes[path] = &memNode{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+// children returns the paths directly inside a directory; the lock must be held
+func (m *MemFS) children(dir string) []string {
+	var children []string
+	for path := range m.nodes {
+		if path != dir && filepath.Dir(path) == dir {
+			children = append(children, path)
+		}
+	}
+	return children
+}
+
+// descendants returns every path inside a directory; the lock must be held
+func (m *MemFS) descendants(dir string) []string {
+	var descendants []string
+	prefix := dir + string(filepath.Separator)
+	for path := range m.nodes {
+		if strings.HasPrefix(path, prefix) {
+			descendants = append(descendants, path)
+		}
+	}
+	return descendants
+}
+
+// info describes a node under the given name
+func (n *memNode) info(name string) fs.FileInfo {
+	return memInfo{name: name, size: int64(len(n.data)), mode: n.mode, modTime: n.modTime}
+}
+
+// memInfo implements fs.FileInfo for MemFS
+type memInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() fs.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return i.modTime }
+func (i memInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memInfo) Sys() any           { return nil }
+
+// memFile is a file of a MemFS opened for reading
+type memFile struct {
+	*bytes.Reader
+	stat fs.FileInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.stat, nil }
+func (f *memFile) Close() error               { return nil }
+
+// Errors of MemFS operations that have no io/fs equivalent
+var (
+	errIsDir    = errors.New("is a directory")
+	errNotDir   = errors.New("not a directory")
+	errNotEmpty = errors.New("directory not empty")
+)
@@ -0,0 +1,145 @@
+package organizer
+
+import "go-file-organizer/internal/utils"
+
+// Reasons a scanned file is left in place, as reported in Decision.Reason
+const (
+	// ReasonIgnored is a file matched by an ignore pattern
+	ReasonIgnored = "ignored"
+	// ReasonCategory is a file whose category is left in place
+	ReasonCategory = "category"
+	// ReasonOrganized is a file already in its category folder
+	ReasonOrganized = "organized"
+	// ReasonConflict is a file whose destination exists under the skip policy
+	ReasonConflict = "destination exists"
+	// ReasonRejected is a planned move rejected in review
+	ReasonRejected = "rejected"
+)
+
+// Decision is what a run decided to do with a file: move it to Destination,
+// or leave it in place for Reason
+type Decision struct {
+	Path        string `json:"path"`
+	Category    string `json:"category,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	// Metadata is what the classifier learned about a moved file, if anything
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Observer follows a run as it happens, e.g. to render its progress in a GUI
+// or a web front-end. Its methods are called in order from the goroutine
+// running the organizer, so they should return quickly.
+type Observer interface {
+	// ScanProgress is called for every file the scan finds, with the number
+	// found so far
+	ScanProgress(path string, found int)
+	// Decided is called once it is known whether and where a file is moved.
+	// A planned move is decided again when it is rejected in review or its
+	// destination turns out to exist.
+	Decided(decision Decision)
+	// Started is called before the first move with the number of planned moves
+	Started(total int)
+	// Moved is called for every file moved, or that would be moved in a dry run
+	Moved(event Event)
+	// Failed is called for every file or folder that could not be processed
+	Failed(event Event)
+	// Finished is called with the summary once a run ends, also when it
+	// stopped early; a run aborted by an error does not finish
+	Finished(summary *utils.Summary)
+}
+
+// NopObserver ignores every notification. Embed it in a type to implement
+// only the Observer methods it needs.
+type NopObserver struct{}
+
+// ScanProgress implements Observer
+func (NopObserver) ScanProgress(string, int) {}
+
+// Decided implements Observer
+func (NopObserver) Decided(Decision) {}
+
+// Started implements Observer
+func (NopObserver) Started(int) {}
+
+// Moved implements Observer
+func (NopObserver) Moved(Event) {}
+
+// Failed implements Observer
+func (NopObserver) Failed(Event) {}
+
+// Finished implements Observer
+func (NopObserver) Finished(*utils.Summary) {}
+
+// multiObserver notifies several observers in turn
+type multiObserver []Observer
+
+// MultiObserver returns an observer that notifies each of the given observers
+// in turn. Nil observers are left out.
+func MultiObserver(observers ...Observer) Observer {
+	var multi multiObserver
+	for _, observer := range observers {
+		if observer != nil {
+			multi = append(multi, observer)
+		}
+	}
+	switch len(multi) {
+	case 0:
+		return nil
+	case 1:
+		return multi[0]
+	}
+	return multi
+}
+
+func (m multiObserver) ScanProgress(path string, found int) {
+	for _, observer := range m {
+		observer.ScanProgress(path, found)
+	}
+}
+
+func (m multiObserver) Decided(decision Decision) {
+	for _, observer := range m {
+		observer.Decided(decision)
+	}
+}
+
+func (m multiObserver) Started(total int) {
+	for _, observer := range m {
+		observer.Started(total)
+	}
+}
+
+func (m multiObserver) Moved(event Event) {
+	for _, observer := range m {
+		observer.Moved(event)
+	}
+}
+
+func (m multiObserver) Failed(event Event) {
+	for _, observer := range m {
+		observer.Failed(event)
+	}
+}
+
+func (m multiObserver) Finished(summary *utils.Summary) {
+	for _, observer := range m {
+		observer.Finished(summary)
+	}
+}
+
+// observer returns the observer of a run: Observer, along with a terminal
+// progress bar with ShowProgress
+func (o Options) observer() Observer {
+	if !o.ShowProgress {
+		return o.Observer
+	}
+	return MultiObserver(NewProgressBar(o.output()), o.Observer)
+}
+
+// decided reports a decision to the observer, if one is set
+func (o Options) decided(decision Decision) {
+	if o.Observer != nil {
+		o.Observer.Decided(decision)
+	}
+}
@@ -1,149 +1,180 @@
 package organizer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go-file-organizer/internal/utils"
+	"io"
 	"os"
-	"os/signal"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/schollz/progressbar/v3"
 )
 
 // OrganizeFiles organizes files in the given directory by their categories
 func OrganizeFiles(rootPath string, isDryRun bool, logger *utils.Logger) (*utils.Summary, error) {
-	return OrganizeFilesWithConfig(rootPath, isDryRun, logger, nil, nil, false)
+	return OrganizeFilesWithConfig(context.Background(), rootPath, isDryRun, logger, nil, nil, false)
 }
 
-// OrganizeFilesWithConfig organizes files with custom configuration and ignore rules
-func OrganizeFilesWithConfig(rootPath string, isDryRun bool, logger *utils.Logger, extensionMapping *utils.ExtensionMapping, ignoreManager *utils.IgnoreManager, showProgress bool) (*utils.Summary, error) {
-	summary := &utils.Summary{}
+// Options configures an organization or watch run
+type Options struct {
+	// RootPath is the directory whose files are organized
+	RootPath string
+	// Files lists the files to organize instead of every file under RootPath,
+	// e.g. the output of find; they must be inside RootPath, against which
+	// relative names are resolved (nil to walk RootPath)
+	Files []string
+	// Destination is where category folders are created; defaults to RootPath.
+	// Relative destinations are resolved against RootPath.
+	Destination string
+	// DryRun previews the actions without touching the filesystem
+	DryRun bool
+	// Logger receives the operation log, e.g. a *utils.Logger or any other
+	// utils.LogSink (nil to disable)
+	Logger utils.LogSink
+	// ExtensionMapping overrides the default extension mappings (nil for defaults)
+	ExtensionMapping *utils.ExtensionMapping
+	// IgnoreManager skips matching files and directories (nil to ignore nothing)
+	IgnoreManager *utils.IgnoreManager
+	// ShowProgress renders a progress bar instead of per-file output, by adding
+	// a ProgressBar to the observers of the run
+	ShowProgress bool
+	// ConflictPolicy decides what happens when the destination file exists
+	ConflictPolicy utils.ConflictPolicy
+	// UnknownPolicy decides what happens to files without a known category
+	UnknownPolicy utils.UnknownPolicy
+	// UnknownFolder is the folder used by the move and group unknown policies
+	// (defaults to DefaultMiscFolder and DefaultOtherFolder)
+	UnknownFolder string
+	// SniffContent detects the category of uncategorized files from their
+	// content, by adding a ContentClassifier at the end of the chain
+	SniffContent bool
+	// Classifiers are consulted in order before the extension lookup; the
+	// first one that recognizes a file decides its category (nil for none)
+	Classifiers []Classifier
+	// SkipCategories lists additional categories whose files are left in place
+	SkipCategories []string
+	// Output receives the human-readable progress text (nil for os.Stdout)
+	Output io.Writer
+	// OnEvent receives a structured event for every action (nil to disable)
+	OnEvent func(Event)
+	// Observer follows the scan, the decisions, the moves and the summary of a
+	// run, e.g. to render its progress (nil to disable)
+	Observer Observer
+	// Script receives the moves of a dry run as a POSIX shell script (nil to disable)
+	Script io.Writer
+	// Journal records the completed moves so that they can be undone (nil to disable)
+	Journal *Journal
+	// Review can confirm or change the planned moves before any is made, e.g.
+	// Reviewer.Review. It edits the plan's operations in place; an error aborts
+	// the run (nil to skip the review).
+	Review func(plan *Plan) error
+	// FailOnError stops the run at the first file that cannot be moved instead
+	// of recording the failure and carrying on with the others
+	FailOnError bool
+	// FS is the filesystem of the files to organize (nil for the local filesystem)
+	FS FS
+}
 
-	// First, scan all files to get categories
-	categories, err := ScanFilesWithConfig(rootPath, extensionMapping, ignoreManager)
-	if err != nil {
-		return summary, fmt.Errorf("failed to scan files: %v", err)
-	}
+// ErrMoveFailed is returned, wrapped with the file and the reason, when a move
+// fails and Options.FailOnError is set. The moves made before it are kept.
+var ErrMoveFailed = errors.New("move failed")
 
-	// Count total files and prepare progress bar
-	totalFiles := 0
-	for _, files := range categories {
-		totalFiles += len(files)
-	}
-	summary.FilesScanned = totalFiles
-
-	var bar *progressbar.ProgressBar
-	if showProgress && totalFiles > 0 {
-		bar = progressbar.NewOptions(totalFiles,
-			progressbar.OptionSetDescription("Organizing files"),
-			progressbar.OptionSetWidth(40),
-			progressbar.OptionShowCount(),
-			progressbar.OptionSetRenderBlankState(true),
-			progressbar.OptionEnableColorCodes(true),
-			progressbar.OptionSetTheme(progressbar.Theme{
-				Saucer:        "[green]=[reset]",
-				SaucerHead:    "[green]>[reset]",
-				SaucerPadding: " ",
-				BarStart:      "[",
-				BarEnd:        "]",
-			}),
-		)
-		defer func() {
-			if bar != nil {
-				bar.Finish()
-				fmt.Println() // Add newline after progress bar
-			}
-		}()
-	}
+// ErrInterrupted is returned, wrapped with the context's error, when the
+// context of a run is cancelled or its deadline passes before the run
+// finished. The moves made before it are kept.
+var ErrInterrupted = errors.New("interrupted")
 
-	// Process each category
-	for category, files := range categories {
-		// Skip categories that shouldn't be organized (like "Unknown" or "No Extension")
-		if shouldSkipCategory(category) {
-			summary.FilesSkipped += len(files)
-			// Update progress bar for skipped files
-			if bar != nil {
-				for range files {
-					bar.Add(1)
-				}
-			}
-			continue
-		}
+// interrupted returns an error wrapping ErrInterrupted and the context's error
+// once the context is done, and nil before
+func interrupted(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInterrupted, err)
+	}
+	return nil
+}
 
-		// Create category folder
-		categoryPath := filepath.Join(rootPath, category)
-		if err := createCategoryFolder(categoryPath, isDryRun, logger); err != nil {
-			logger.LogError("Folder creation", categoryPath, err)
-			continue
-		}
-		summary.FoldersCreated++
+// StoppedEarly reports whether a run ended before its last move because of
+// FailOnError or its context, in which case the moves made so far are still summarized
+func StoppedEarly(err error) bool {
+	return errors.Is(err, ErrMoveFailed) || errors.Is(err, ErrInterrupted)
+}
 
-		// Move files to category folder
-		for _, filePath := range files {
-			fileName := filepath.Base(filePath)
-			destPath := filepath.Join(categoryPath, fileName)
+// output returns the writer for human-readable text
+func (o Options) output() io.Writer {
+	if o.Output == nil {
+		return os.Stdout
+	}
+	return o.Output
+}
 
-			// Skip if file is already in the target directory
-			if filepath.Dir(filePath) == categoryPath {
-				if bar != nil {
-					bar.Add(1)
-				}
-				continue
-			}
+// destinationRoot returns the directory in which category folders are created
+func (o Options) destinationRoot() string {
+	if o.Destination == "" {
+		return o.RootPath
+	}
+	if filepath.IsAbs(o.Destination) {
+		return o.Destination
+	}
+	return filepath.Join(o.RootPath, o.Destination)
+}
 
-			if isDryRun {
-				logger.LogDryRun(filePath, destPath)
-				if !showProgress {
-					fmt.Printf("  [DRY-RUN] Would move: %s -> %s\n", filePath, destPath)
-				}
-			} else {
-				if err := moveFile(filePath, destPath); err != nil {
-					logger.LogError("Move", filePath, err)
-					if !showProgress {
-						fmt.Printf("  [ERROR] Failed to move %s: %v\n", filePath, err)
-					}
-					if bar != nil {
-						bar.Add(1)
-					}
-					continue
-				}
-				logger.LogMove(filePath, destPath)
-				if !showProgress {
-					fmt.Printf("  [MOVED] %s -> %s\n", filePath, destPath)
-				}
-			}
-			summary.FilesMoved++
+// OrganizeFilesWithConfig organizes files with custom configuration and ignore
+// rules until ctx is done
+func OrganizeFilesWithConfig(ctx context.Context, rootPath string, isDryRun bool, logger *utils.Logger, extensionMapping *utils.ExtensionMapping, ignoreManager *utils.IgnoreManager, showProgress bool) (*utils.Summary, error) {
+	return Organize(ctx, Options{
+		RootPath:         rootPath,
+		DryRun:           isDryRun,
+		Logger:           logger,
+		ExtensionMapping: extensionMapping,
+		IgnoreManager:    ignoreManager,
+		ShowProgress:     showProgress,
+	})
+}
 
-			// Update progress bar
-			if bar != nil {
-				bar.Add(1)
-			}
+// Organize organizes files according to the given options. Once ctx is done
+// the run stops before the next move and returns an error wrapping
+// ErrInterrupted with the summary of what was done.
+func Organize(ctx context.Context, opts Options) (*utils.Summary, error) {
+	start := time.Now()
+	// Every record of the run shares one session
+	opts.Logger = utils.AsLogger(opts.Logger)
+	opts.Observer = opts.observer()
+	summary := utils.NewSummary()
+	defer func() {
+		summary.Duration = time.Since(start)
+	}()
+
+	// Decide every move first, then carry out the plan
+	plan, err := buildPlan(ctx, opts, summary)
+	if err != nil && !StoppedEarly(err) {
+		return summary, err
+	}
+	if err == nil {
+		if err := reviewPlan(plan, opts, summary); err != nil {
+			return summary, err
+		}
+		err = executePlan(ctx, plan, opts, summary)
+		if err != nil && !StoppedEarly(err) {
+			return summary, err
 		}
 	}
 
 	// Log summary
-	logger.LogSummary(*summary)
-
-	return summary, nil
-}
+	summary.Duration = time.Since(start)
+	utils.AsLogger(opts.Logger).LogSummary(*summary)
+	opts.emit(Event{Type: EventSummary, Summary: summary})
 
-// shouldSkipCategory determines if a category should be skipped during organization
-func shouldSkipCategory(category string) bool {
-	skipCategories := map[string]bool{
-		"Unknown":      true,
-		"No Extension": true,
-	}
-	return skipCategories[category]
+	return summary, err
 }
 
 // createCategoryFolder creates a folder for the category if it doesn't exist
-func createCategoryFolder(folderPath string, isDryRun bool, logger *utils.Logger) error {
+func createCategoryFolder(fsys FS, folderPath string, isDryRun bool, logger *utils.Logger) error {
 	// Check if folder already exists
-	if _, err := os.Stat(folderPath); err == nil {
+	if _, err := fsys.Stat(folderPath); err == nil {
 		return nil // Folder already exists
 	}
 
@@ -153,7 +184,7 @@ func createCategoryFolder(folderPath string, isDryRun bool, logger *utils.Logger
 	}
 
 	// Create the folder
-	if err := os.MkdirAll(folderPath, 0755); err != nil {
+	if err := fsys.MkdirAll(folderPath, 0755); err != nil {
 		return fmt.Errorf("failed to create folder %s: %v", folderPath, err)
 	}
 
@@ -161,55 +192,199 @@ func createCategoryFolder(folderPath string, isDryRun bool, logger *utils.Logger
 	return nil
 }
 
-// moveFile moves a file from source to destination
-func moveFile(source, destination string) error {
+// resolveConflict applies the conflict policy to a destination path.
+// It returns the path to move to, or skip=true if the file should be left in place.
+func resolveConflict(fsys FS, destination string, policy utils.ConflictPolicy) (string, bool, error) {
+	if _, err := fsys.Stat(destination); err != nil {
+		return destination, false, nil
+	}
+
+	switch policy {
+	case utils.ConflictSkip:
+		return destination, true, nil
+	case utils.ConflictOverwrite:
+		return destination, false, nil
+	case utils.ConflictRename:
+		renamed, err := nextAvailableName(fsys, destination)
+		return renamed, false, err
+	default:
+		return destination, false, fmt.Errorf("destination file already exists: %s", destination)
+	}
+}
+
+// maxRenameAttempts is how many "name (n).ext" variants the rename policy
+// tries before giving up
+const maxRenameAttempts = 10000
+
+// nextAvailableName returns the first free "name (n).ext" variant of a path
+func nextAvailableName(fsys FS, path string) (string, error) {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; i <= maxRenameAttempts; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		_, err := fsys.Stat(candidate)
+		if os.IsNotExist(err) {
+			return candidate, nil
+		}
+		if err != nil {
+			return path, fmt.Errorf("failed to check %s: %v", candidate, err)
+		}
+	}
+	return path, fmt.Errorf("no free name for %s after %d attempts", path, maxRenameAttempts)
+}
+
+// moveFile moves a file from source to destination.
+// An existing destination is only replaced under the overwrite policy.
+func moveFile(fsys FS, source, destination string, policy utils.ConflictPolicy) error {
 	// Check if destination already exists
-	if _, err := os.Stat(destination); err == nil {
-		return fmt.Errorf("destination file already exists: %s", destination)
+	if _, err := fsys.Stat(destination); err == nil {
+		if policy != utils.ConflictOverwrite {
+			return fmt.Errorf("destination file already exists: %s", destination)
+		}
+		if err := fsys.Remove(destination); err != nil {
+			return fmt.Errorf("failed to replace existing file: %v", err)
+		}
 	}
 
 	// Ensure destination directory exists
 	destDir := filepath.Dir(destination)
-	if err := os.MkdirAll(destDir, 0755); err != nil {
+	if err := fsys.MkdirAll(destDir, 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %v", err)
 	}
 
-	// Move the file
-	if err := os.Rename(source, destination); err != nil {
+	// Move the file, copying it if the destination is on another device
+	if err := renameOrCopy(fsys, source, destination); err != nil {
 		return fmt.Errorf("failed to move file: %v", err)
 	}
 
 	return nil
 }
 
+// maxSummaryExtensions is the number of extensions listed by PrintSummary
+const maxSummaryExtensions = 10
+
 // PrintSummary prints a clean summary of the organization process
 func PrintSummary(summary *utils.Summary, isDryRun bool) {
+	FprintSummary(os.Stdout, summary, isDryRun)
+}
+
+// FprintSummary writes a formatted summary of the organization results to w
+func FprintSummary(w io.Writer, summary *utils.Summary, isDryRun bool) {
 	separator := strings.Repeat("=", 50)
 
-	fmt.Println("\n" + separator)
+	fmt.Fprintln(w, "\n"+separator)
 	if isDryRun {
-		fmt.Println("📋 DRY-RUN SUMMARY")
+		fmt.Fprintln(w, "📋 DRY-RUN SUMMARY")
 	} else {
-		fmt.Println("📋 ORGANIZATION SUMMARY")
+		fmt.Fprintln(w, "📋 ORGANIZATION SUMMARY")
 	}
-	fmt.Println(separator)
+	fmt.Fprintln(w, separator)
 
-	fmt.Printf("✅  Total files scanned: %d\n", summary.FilesScanned)
+	fmt.Fprintf(w, "✅  Total files scanned: %d\n", summary.FilesScanned)
 
 	if isDryRun {
-		fmt.Printf("🔮  Files that would be moved: %d\n", summary.FilesMoved)
-		fmt.Printf("📁  Folders that would be created: %d\n", summary.FoldersCreated)
+		fmt.Fprintf(w, "🔮  Files that would be moved: %d (%s)\n", summary.FilesMoved, utils.FormatBytes(summary.BytesMoved))
+		fmt.Fprintf(w, "📁  Folders that would be created: %d\n", summary.FoldersCreated)
 	} else {
-		fmt.Printf("🔀  Files moved: %d\n", summary.FilesMoved)
-		fmt.Printf("📁  Folders created: %d\n", summary.FoldersCreated)
+		fmt.Fprintf(w, "🔀  Files moved: %d (%s)\n", summary.FilesMoved, utils.FormatBytes(summary.BytesMoved))
+		fmt.Fprintf(w, "📁  Folders created: %d\n", summary.FoldersCreated)
+	}
+
+	fmt.Fprintf(w, "🚫  Skipped (left in place): %d\n", summary.FilesSkipped)
+	if summary.SkippedByCategory > 0 {
+		fmt.Fprintf(w, "    by category: %d\n", summary.SkippedByCategory)
+	}
+	if summary.SkippedByConflict > 0 {
+		fmt.Fprintf(w, "    by conflict: %d\n", summary.SkippedByConflict)
+	}
+	if summary.SkippedByReview > 0 {
+		fmt.Fprintf(w, "    in review: %d\n", summary.SkippedByReview)
+	}
+	if summary.SkippedByIgnore > 0 || summary.DirectoriesIgnored > 0 {
+		fmt.Fprintf(w, "🙈  Ignored: %d files, %d directories\n", summary.SkippedByIgnore, summary.DirectoriesIgnored)
+	}
+	if summary.AlreadyOrganized > 0 {
+		fmt.Fprintf(w, "👌  Already organized: %d\n", summary.AlreadyOrganized)
+	}
+	if summary.ConflictsResolved > 0 {
+		fmt.Fprintf(w, "🔁  Conflicts resolved: %d\n", summary.ConflictsResolved)
+	}
+	fmt.Fprintf(w, "❌  Failures: %d\n", len(summary.Failures))
+	duration := summary.Duration.Round(time.Millisecond)
+	if summary.Duration < time.Second {
+		duration = summary.Duration.Round(time.Microsecond)
+	}
+	fmt.Fprintf(w, "⏱️  Duration: %s (%.1f files/s, %s/s)\n",
+		duration, summary.FilesPerSecond(), utils.FormatBytes(int64(summary.BytesPerSecond())))
+
+	// Per-category breakdown
+	if len(summary.Categories) > 0 {
+		fmt.Fprintln(w, separator)
+		fmt.Fprintln(w, "📂 By category:")
+		for _, name := range summary.CategoryNames() {
+			stats := summary.Categories[name]
+			fmt.Fprintf(w, "    %-20s %6d files %12s\n", name, stats.Files, utils.FormatBytes(stats.Bytes))
+		}
 	}
 
-	fmt.Printf("🚫  Skipped (unknown/no extension): %d\n", summary.FilesSkipped)
-	fmt.Println(separator)
+	// Most common extensions
+	if extensions := summary.TopExtensions(); len(extensions) > 0 {
+		fmt.Fprintln(w, separator)
+		fmt.Fprintln(w, "🏷️  By extension:")
+		for i, ext := range extensions {
+			if i == maxSummaryExtensions {
+				fmt.Fprintf(w, "    ... and %d more\n", len(extensions)-maxSummaryExtensions)
+				break
+			}
+			name := ext
+			if name == "" {
+				name = "(none)"
+			}
+			fmt.Fprintf(w, "    %-20s %6d files\n", name, summary.Extensions[ext])
+		}
+	}
+
+	// Every failure with its reason
+	if len(summary.Failures) > 0 {
+		fmt.Fprintln(w, separator)
+		fmt.Fprintln(w, "❌ Failures:")
+		for _, failure := range summary.Failures {
+			fmt.Fprintf(w, "    %s: %s: %s\n", failure.Operation, failure.Path, failure.Reason)
+		}
+	}
+
+	fmt.Fprintln(w, separator)
+}
+
+// StartWatchMode starts watching the directory for new files and organizes them
+// automatically until ctx is done
+func StartWatchMode(ctx context.Context, rootPath string, isDryRun bool, logger *utils.Logger, extensionMapping *utils.ExtensionMapping, ignoreManager *utils.IgnoreManager, showProgress bool) error {
+	return Watch(ctx, Options{
+		RootPath:         rootPath,
+		DryRun:           isDryRun,
+		Logger:           logger,
+		ExtensionMapping: extensionMapping,
+		IgnoreManager:    ignoreManager,
+		ShowProgress:     showProgress,
+	})
 }
 
-// StartWatchMode starts watching the directory for new files and organizes them automatically
-func StartWatchMode(rootPath string, isDryRun bool, logger *utils.Logger, extensionMapping *utils.ExtensionMapping, ignoreManager *utils.IgnoreManager, showProgress bool) error {
+// Watch watches the root directory for new files and organizes them according
+// to the options, and returns nil once ctx is done. With FailOnError it stops
+// at the first file that cannot be moved and returns an error wrapping
+// ErrMoveFailed. New files are reported by the local filesystem, so Options.FS
+// must give access to the same tree.
+func Watch(ctx context.Context, opts Options) error {
+	opts.Observer = opts.observer()
+	fsys := opts.filesystem()
+	rootPath := opts.RootPath
+	isDryRun := opts.DryRun
+	logger := utils.AsLogger(opts.Logger)
+	classifiers := opts.classifiers()
+	ignoreManager := opts.IgnoreManager
+	destinationRoot := opts.destinationRoot()
+	out := opts.output()
+
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return fmt.Errorf("failed to create file watcher: %v", err)
@@ -222,10 +397,6 @@ func StartWatchMode(rootPath string, isDryRun bool, logger *utils.Logger, extens
 		return fmt.Errorf("failed to watch directory %s: %v", rootPath, err)
 	}
 
-	// Channel to listen for interrupt signals
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
-
 	// Debounce duplicate events (some file operations trigger multiple events)
 	eventDebounce := make(map[string]time.Time)
 	debounceDelay := 500 * time.Millisecond
@@ -247,84 +418,90 @@ func StartWatchMode(rootPath string, isDryRun bool, logger *utils.Logger, extens
 				eventDebounce[event.Name] = now
 
 				// Check if it's a regular file (not a directory)
-				fileInfo, err := os.Stat(event.Name)
+				fileInfo, err := fsys.Stat(event.Name)
 				if err != nil || fileInfo.IsDir() {
 					continue
 				}
 
 				// Check if file should be ignored
 				if ignoreManager != nil && ignoreManager.ShouldIgnore(event.Name) {
-					if logger != nil {
-						logger.LogMove(event.Name, "IGNORED: "+event.Name)
-					}
+					opts.decided(Decision{Path: event.Name, Reason: ReasonIgnored})
+					logger.Debug("[IGNORED] "+event.Name, utils.LogFields{Op: "ignore", Src: event.Name})
 					continue
 				}
 
-				// Get file extension and category
-				extension := strings.ToLower(filepath.Ext(event.Name))
-				if extension == "" {
-					if logger != nil {
-						logger.LogMove(event.Name, "SKIPPED: no extension")
+				// Ask the classifiers for the category
+				classification, err := classify(classifiers, NewCandidate(fsys, event.Name, fileInfo))
+				if err != nil {
+					opts.emit(Event{Type: EventError, Path: event.Name, Reason: err.Error()})
+					fmt.Fprintf(out, "❌ [WATCH] Error classifying file %s: %v\n", event.Name, err)
+					logger.LogError("Classify", event.Name, err)
+					if opts.FailOnError {
+						return fmt.Errorf("%w: %s: %v", ErrMoveFailed, event.Name, err)
 					}
 					continue
 				}
+				category, metadata := classification.Category, classification.Metadata
 
-				var category string
-				var exists bool
-				if extensionMapping != nil {
-					category, exists = extensionMapping.GetMapping(extension)
-				} else {
-					category, exists = extensionCategories[extension]
+				// Skip categories that shouldn't be organized
+				category, organize := opts.resolveCategory(event.Name, category)
+				if !organize {
+					opts.emit(Event{Type: EventSkipped, Path: event.Name, Category: category, Reason: ReasonCategory})
+					logger.LogSkipped(utils.LogFields{Src: event.Name, Category: category}, ReasonCategory)
+					continue
 				}
 
-				if !exists {
-					if logger != nil {
-						logger.LogMove(event.Name, "SKIPPED: unknown extension")
+				// Organize the file
+				filename := filepath.Base(event.Name)
+				targetDir := filepath.Join(destinationRoot, category)
+				targetPath, skip, err := resolveConflict(fsys, filepath.Join(targetDir, filename), opts.ConflictPolicy)
+				if err != nil {
+					opts.emit(Event{Type: EventError, Path: event.Name, Category: category, Reason: err.Error()})
+					fmt.Fprintf(out, "❌ [WATCH] Error moving file %s: %v\n", event.Name, err)
+					logger.LogError("File move", event.Name, err)
+					if opts.FailOnError {
+						return fmt.Errorf("%w: %s: %v", ErrMoveFailed, event.Name, err)
 					}
 					continue
 				}
-
-				// Skip categories that shouldn't be organized
-				if shouldSkipCategory(category) {
-					if logger != nil {
-						logger.LogMove(event.Name, "SKIPPED: "+category)
-					}
+				if skip {
+					opts.emit(Event{Type: EventSkipped, Path: event.Name, Destination: targetPath, Category: category, Reason: ReasonConflict})
+					logger.LogSkipped(utils.LogFields{Src: event.Name, Dst: targetPath, Category: category}, ReasonConflict)
 					continue
 				}
-
-				// Organize the file
-				filename := filepath.Base(event.Name)
-				targetDir := filepath.Join(rootPath, category)
-				targetPath := filepath.Join(targetDir, filename)
+				opts.decided(Decision{Path: event.Name, Category: category, Destination: targetPath, Metadata: metadata})
 
 				if isDryRun {
-					fmt.Printf("🔮 [WATCH] Would move: %s → %s/%s\n", event.Name, category, filename)
-					if logger != nil {
-						logger.LogDryRun(event.Name, targetPath)
-					}
+					opts.emit(Event{Type: EventPlanned, Path: event.Name, Destination: targetPath, Category: category, Metadata: metadata})
+					fmt.Fprintf(out, "🔮 [WATCH] Would move: %s → %s/%s\n", event.Name, category, filename)
+					logger.LogPlanned(utils.LogFields{Src: event.Name, Dst: targetPath, Category: category, Bytes: fileInfo.Size()})
 				} else {
 					// Create target directory if it doesn't exist
-					if err := createCategoryFolder(targetDir, false, logger); err != nil {
-						fmt.Printf("❌ [WATCH] Error creating directory %s: %v\n", targetDir, err)
-						if logger != nil {
-							logger.LogError("Folder creation", targetDir, err)
+					if err := createCategoryFolder(fsys, targetDir, false, logger); err != nil {
+						opts.emit(Event{Type: EventError, Path: event.Name, Category: category, Reason: err.Error()})
+						fmt.Fprintf(out, "❌ [WATCH] Error creating directory %s: %v\n", targetDir, err)
+						logger.LogError("Folder creation", targetDir, err)
+						if opts.FailOnError {
+							return fmt.Errorf("%w: %s: %v", ErrMoveFailed, event.Name, err)
 						}
 						continue
 					}
 
 					// Move the file
-					if err := moveFile(event.Name, targetPath); err != nil {
-						fmt.Printf("❌ [WATCH] Error moving file %s: %v\n", event.Name, err)
-						if logger != nil {
-							logger.LogError("File move", event.Name, err)
+					if err := moveFile(fsys, event.Name, targetPath, opts.ConflictPolicy); err != nil {
+						opts.emit(Event{Type: EventError, Path: event.Name, Destination: targetPath, Category: category, Reason: err.Error()})
+						fmt.Fprintf(out, "❌ [WATCH] Error moving file %s: %v\n", event.Name, err)
+						logger.LogError("File move", event.Name, err)
+						if opts.FailOnError {
+							return fmt.Errorf("%w: %s: %v", ErrMoveFailed, event.Name, err)
 						}
 						continue
 					}
 
-					fmt.Printf("✅ [WATCH] Moved: %s → %s/%s\n", filename, category, filename)
-					if logger != nil {
-						logger.LogMove(event.Name, targetPath)
-					}
+					opts.Journal.Record(event.Name, targetPath, category)
+					opts.emit(Event{Type: EventMoved, Path: event.Name, Destination: targetPath, Category: category, Metadata: metadata})
+					fmt.Fprintf(out, "✅ [WATCH] Moved: %s → %s/%s\n", filename, category, filepath.Base(targetPath))
+					logger.LogMoved(utils.LogFields{Src: event.Name, Dst: targetPath, Category: category, Bytes: fileInfo.Size()})
 				}
 			}
 
@@ -332,13 +509,11 @@ func StartWatchMode(rootPath string, isDryRun bool, logger *utils.Logger, extens
 			if !ok {
 				return nil
 			}
-			fmt.Printf("⚠️  [WATCH] Watcher error: %v\n", err)
-			if logger != nil {
-				logger.LogError("Watcher", "filesystem", err)
-			}
+			fmt.Fprintf(out, "⚠️  [WATCH] Watcher error: %v\n", err)
+			logger.LogError("Watcher", "filesystem", err)
 
-		case <-interrupt:
-			fmt.Println("\n🛑 Watch mode stopped by user")
+		case <-ctx.Done():
+			fmt.Fprintln(out, "\n🛑 Watch mode stopped")
 			return nil
 		}
 	}
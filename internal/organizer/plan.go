@@ -0,0 +1,348 @@
+package organizer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// PlanVersion is the format version of plan files written by SavePlan
+const PlanVersion = 1
+
+// Plan is the list of moves an organization run intends to make. A plan can be
+// saved, reviewed and edited, then executed later with ApplyPlan.
+type Plan struct {
+	Version        int                  `json:"version"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	RootPath       string               `json:"rootPath"`
+	ConflictPolicy utils.ConflictPolicy `json:"conflictPolicy,omitempty"`
+	Operations     []Operation          `json:"operations"`
+}
+
+// Operation is a single planned move. The size and modification time of the
+// source are recorded when planning so that changed files can be refused.
+type Operation struct {
+	Source      string    `json:"source"`
+	Destination string    `json:"destination"`
+	Category    string    `json:"category"`
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"modTime"`
+	// Metadata is what the classifier learned about the file, if anything
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// BuildPlan scans the root directory and returns the moves Organize would make,
+// without touching the filesystem. The summary counts the scanned, ignored and
+// skipped files. Once ctx is done the scan stops with an error wrapping
+// ErrInterrupted.
+func BuildPlan(ctx context.Context, opts Options) (*Plan, *utils.Summary, error) {
+	start := time.Now()
+	// Every record of the run shares one session
+	opts.Logger = utils.AsLogger(opts.Logger)
+	opts.Observer = opts.observer()
+	summary := utils.NewSummary()
+	plan, err := buildPlan(ctx, opts, summary)
+	summary.Duration = time.Since(start)
+	return plan, summary, err
+}
+
+// buildPlan scans the root directory and records the planned moves
+func buildPlan(ctx context.Context, opts Options, summary *utils.Summary) (*Plan, error) {
+	destinationRoot := opts.destinationRoot()
+	logger := utils.AsLogger(opts.Logger)
+	fsys := opts.filesystem()
+
+	scanned, err := opts.scan(ctx, summary)
+	if errors.Is(err, ErrInterrupted) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %v", err)
+	}
+	for _, failure := range summary.Failures {
+		opts.emit(Event{Type: EventError, Path: failure.Path, Reason: failure.Reason})
+	}
+
+	plan := &Plan{
+		Version:        PlanVersion,
+		CreatedAt:      time.Now(),
+		RootPath:       opts.RootPath,
+		ConflictPolicy: opts.ConflictPolicy,
+		Operations:     []Operation{},
+	}
+
+	for category, files := range scanned.categories {
+		for _, filePath := range files {
+			summary.RecordScanned(filePath)
+
+			// Resolve the target folder, applying the unknown file policy and skip list
+			target, ok := opts.resolveCategory(filePath, category)
+			if !ok {
+				summary.FilesSkipped++
+				summary.SkippedByCategory++
+				opts.emit(Event{Type: EventSkipped, Path: filePath, Category: target, Reason: ReasonCategory})
+				logger.Debug("[SKIP] Category left in place: "+filePath, utils.LogFields{Op: "skip", Src: filePath, Category: target})
+				continue
+			}
+
+			// Skip if file is already in the target directory
+			categoryPath := filepath.Join(destinationRoot, target)
+			if filepath.Dir(filePath) == categoryPath {
+				summary.AlreadyOrganized++
+				opts.decided(Decision{Path: filePath, Category: target, Reason: ReasonOrganized})
+				logger.Debug("[SKIP] Already organized: "+filePath, utils.LogFields{Op: "skip", Src: filePath, Category: target})
+				continue
+			}
+
+			info, err := fsys.Stat(filePath)
+			if err != nil {
+				summary.RecordFailure("Scan", filePath, err)
+				opts.emit(Event{Type: EventError, Path: filePath, Category: target, Reason: err.Error()})
+				continue
+			}
+
+			op := Operation{
+				Source:      filePath,
+				Destination: filepath.Join(categoryPath, filepath.Base(filePath)),
+				Category:    target,
+				Size:        info.Size(),
+				ModTime:     info.ModTime(),
+				Metadata:    scanned.metadata[filePath],
+			}
+			plan.Operations = append(plan.Operations, op)
+			opts.decided(Decision{Path: op.Source, Category: op.Category, Destination: op.Destination, Metadata: op.Metadata})
+		}
+	}
+
+	// A stable order keeps saved plans easy to review and diff
+	sort.Slice(plan.Operations, func(i, j int) bool {
+		if plan.Operations[i].Category != plan.Operations[j].Category {
+			return plan.Operations[i].Category < plan.Operations[j].Category
+		}
+		return plan.Operations[i].Source < plan.Operations[j].Source
+	})
+
+	return plan, nil
+}
+
+// SavePlan writes a plan as indented JSON
+func SavePlan(plan *Plan, path string) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write plan file: %v", err)
+	}
+	return nil
+}
+
+// LoadPlan reads and validates a plan file
+func LoadPlan(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %v", err)
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan file %s: %v", path, err)
+	}
+
+	if plan.Version != PlanVersion {
+		return nil, fmt.Errorf("unsupported plan version %d, expected %d", plan.Version, PlanVersion)
+	}
+	if _, err := utils.ParseConflictPolicy(string(plan.ConflictPolicy)); err != nil {
+		return nil, fmt.Errorf("invalid plan file %s: %v", path, err)
+	}
+	for i, op := range plan.Operations {
+		if op.Source == "" || op.Destination == "" {
+			return nil, fmt.Errorf("invalid plan file %s: operation %d needs a source and a destination", path, i+1)
+		}
+	}
+
+	return &plan, nil
+}
+
+// ApplyPlan executes a reviewed plan. Operations whose source no longer matches
+// the size and modification time recorded when planning are refused and
+// reported as failures. The plan's conflict policy is used. Once ctx is done
+// the run stops before the next move, as with Organize.
+func ApplyPlan(ctx context.Context, plan *Plan, opts Options) (*utils.Summary, error) {
+	start := time.Now()
+	summary := utils.NewSummary()
+	opts.ConflictPolicy = plan.ConflictPolicy
+	// Every record of the run shares one session
+	opts.Logger = utils.AsLogger(opts.Logger)
+	opts.Observer = opts.observer()
+
+	for _, op := range plan.Operations {
+		summary.RecordScanned(op.Source)
+	}
+	if err := reviewPlan(plan, opts, summary); err != nil {
+		return summary, err
+	}
+	err := executePlan(ctx, plan, opts, summary)
+	if err != nil && !StoppedEarly(err) {
+		return summary, err
+	}
+
+	summary.Duration = time.Since(start)
+	utils.AsLogger(opts.Logger).LogSummary(*summary)
+	opts.emit(Event{Type: EventSummary, Summary: summary})
+	return summary, err
+}
+
+// executePlan performs (or, in a dry run, reports) the planned moves. Failed
+// moves are recorded in the summary. An error is returned if the dry-run
+// script cannot be written, or when the run stops early: at the first failure
+// with FailOnError, or once ctx is done.
+func executePlan(ctx context.Context, plan *Plan, opts Options, summary *utils.Summary) error {
+	isDryRun := opts.DryRun
+	logger := utils.AsLogger(opts.Logger)
+	fsys := opts.filesystem()
+	showProgress := opts.ShowProgress
+	out := opts.output()
+
+	var script *scriptWriter
+	if isDryRun && opts.Script != nil {
+		script = newScriptWriter(opts.Script, plan.RootPath, opts.ConflictPolicy)
+	}
+
+	if opts.Observer != nil {
+		opts.Observer.Started(len(plan.Operations))
+	}
+
+	// fail records an operation that could not be performed and returns an
+	// error if the run must stop because of it
+	fail := func(operation string, op Operation, err error) error {
+		summary.RecordFailure(operation, op.Source, err)
+		opts.emit(Event{Type: EventError, Path: op.Source, Destination: op.Destination, Category: op.Category, Reason: err.Error()})
+		logger.LogError(operation, op.Source, err)
+		if !showProgress {
+			fmt.Fprintf(out, "  [ERROR] Failed to move %s: %v\n", op.Source, err)
+		}
+		if opts.FailOnError {
+			return fmt.Errorf("%w: %s: %v", ErrMoveFailed, op.Source, err)
+		}
+		return nil
+	}
+
+	// Category folders are created once, before the first file is moved into them
+	folderErrors := make(map[string]error)
+	for _, op := range plan.Operations {
+		if err := interrupted(ctx); err != nil {
+			return err
+		}
+
+		folder := filepath.Dir(op.Destination)
+		folderErr, seen := folderErrors[folder]
+		if !seen {
+			_, statErr := fsys.Stat(folder)
+			folderErr = createCategoryFolder(fsys, folder, isDryRun, logger)
+			folderErrors[folder] = folderErr
+			if script != nil {
+				script.Mkdir(folder)
+			}
+			if folderErr != nil {
+				logger.LogError("Folder creation", folder, folderErr)
+			} else {
+				summary.FoldersCreated++
+				if os.IsNotExist(statErr) && !isDryRun {
+					opts.emit(Event{Type: EventCreated, Path: folder, Category: op.Category})
+				}
+			}
+		}
+		if folderErr != nil {
+			// Every file of the folder stays in place
+			if stop := fail("Folder creation", op, folderErr); stop != nil {
+				return stop
+			}
+			continue
+		}
+
+		// Refuse sources that changed since the plan was made
+		if err := verifySource(fsys, op); err != nil {
+			if stop := fail("Verify", op, err); stop != nil {
+				return stop
+			}
+			continue
+		}
+
+		// Apply the conflict policy if the destination is taken
+		_, statErr := fsys.Stat(op.Destination)
+		conflict := statErr == nil
+		destPath, skip, err := resolveConflict(fsys, op.Destination, opts.ConflictPolicy)
+		if err != nil {
+			if stop := fail("Move", op, err); stop != nil {
+				return stop
+			}
+			continue
+		}
+		if skip {
+			summary.FilesSkipped++
+			summary.SkippedByConflict++
+			opts.emit(Event{Type: EventSkipped, Path: op.Source, Destination: destPath, Category: op.Category, Reason: ReasonConflict})
+			logger.LogSkipped(utils.LogFields{Src: op.Source, Dst: destPath, Category: op.Category}, ReasonConflict)
+			if !showProgress {
+				fmt.Fprintf(out, "  [SKIPPED] %s: destination already exists\n", op.Source)
+			}
+			continue
+		}
+
+		if isDryRun {
+			logger.LogPlanned(utils.LogFields{Src: op.Source, Dst: destPath, Category: op.Category, Bytes: op.Size})
+			opts.emit(Event{Type: EventPlanned, Path: op.Source, Destination: destPath, Category: op.Category, Metadata: op.Metadata})
+			if script != nil {
+				script.Move(op.Source, destPath)
+			}
+			if !showProgress {
+				fmt.Fprintf(out, "  [DRY-RUN] Would move: %s -> %s\n", op.Source, destPath)
+			}
+		} else {
+			if err := moveFile(fsys, op.Source, destPath, opts.ConflictPolicy); err != nil {
+				if stop := fail("Move", op, err); stop != nil {
+					return stop
+				}
+				continue
+			}
+			opts.Journal.Record(op.Source, destPath, op.Category)
+			logger.LogMoved(utils.LogFields{Src: op.Source, Dst: destPath, Category: op.Category, Bytes: op.Size})
+			opts.emit(Event{Type: EventMoved, Path: op.Source, Destination: destPath, Category: op.Category, Metadata: op.Metadata})
+			if !showProgress {
+				fmt.Fprintf(out, "  [MOVED] %s -> %s\n", op.Source, destPath)
+			}
+		}
+		summary.RecordMove(op.Category, op.Size)
+		if conflict {
+			summary.ConflictsResolved++
+		}
+	}
+
+	if script != nil && script.err != nil {
+		return fmt.Errorf("failed to write shell script: %v", script.err)
+	}
+	return nil
+}
+
+// verifySource checks that a source file still matches its planned fingerprint
+func verifySource(fsys FS, op Operation) error {
+	info, err := fsys.Stat(op.Source)
+	if err != nil {
+		return fmt.Errorf("source is no longer available: %v", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("source is a directory")
+	}
+	if info.Size() != op.Size || !info.ModTime().Equal(op.ModTime) {
+		return fmt.Errorf("source changed since planning (size %d, modified %s; planned size %d, modified %s)",
+			info.Size(), info.ModTime().Format(time.RFC3339), op.Size, op.ModTime.Format(time.RFC3339))
+	}
+	return nil
+}
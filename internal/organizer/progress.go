@@ -0,0 +1,76 @@
+package organizer
+
+import (
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"io"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// ProgressBar is an Observer that renders the moves of a run as a terminal
+// progress bar, which is what the CLI shows with --progress
+type ProgressBar struct {
+	NopObserver
+	out io.Writer
+	bar *progressbar.ProgressBar
+}
+
+// NewProgressBar returns a progress bar written to out
+func NewProgressBar(out io.Writer) *ProgressBar {
+	return &ProgressBar{out: out}
+}
+
+// Started draws an empty bar for the planned moves
+func (p *ProgressBar) Started(total int) {
+	if total == 0 {
+		return
+	}
+	p.bar = progressbar.NewOptions(total,
+		progressbar.OptionSetDescription("Organizing files"),
+		progressbar.OptionSetWriter(p.out),
+		progressbar.OptionSetWidth(40),
+		progressbar.OptionShowCount(),
+		progressbar.OptionSetRenderBlankState(true),
+		progressbar.OptionEnableColorCodes(true),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "[green]=[reset]",
+			SaucerHead:    "[green]>[reset]",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}),
+	)
+}
+
+// Decided advances the bar for a planned move left in place because of a conflict
+func (p *ProgressBar) Decided(Decision) {
+	p.advance()
+}
+
+// Moved advances the bar
+func (p *ProgressBar) Moved(Event) {
+	p.advance()
+}
+
+// Failed advances the bar
+func (p *ProgressBar) Failed(Event) {
+	p.advance()
+}
+
+// Finished completes the bar
+func (p *ProgressBar) Finished(*utils.Summary) {
+	if p.bar == nil {
+		return
+	}
+	p.bar.Finish()
+	fmt.Fprintln(p.out) // Add newline after progress bar
+	p.bar = nil
+}
+
+// advance counts one planned move as done, once the moves have started
+func (p *ProgressBar) advance() {
+	if p.bar != nil {
+		p.bar.Add(1)
+	}
+}
@@ -0,0 +1,225 @@
+package organizer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ErrReviewAborted is returned when the user quits a review; nothing is moved
+var ErrReviewAborted = errors.New("review aborted, nothing was moved")
+
+// reviewPlan lets opts.Review confirm or change the planned moves and records
+// the operations it dropped as skipped
+func reviewPlan(plan *Plan, opts Options, summary *utils.Summary) error {
+	if opts.Review == nil {
+		return nil
+	}
+	logger := utils.AsLogger(opts.Logger)
+
+	planned := append([]Operation(nil), plan.Operations...)
+	if err := opts.Review(plan); err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool, len(plan.Operations))
+	for _, op := range plan.Operations {
+		kept[op.Source] = true
+	}
+	for _, op := range planned {
+		if kept[op.Source] {
+			continue
+		}
+		summary.FilesSkipped++
+		summary.SkippedByReview++
+		opts.emit(Event{Type: EventSkipped, Path: op.Source, Category: op.Category, Reason: ReasonRejected})
+		logger.LogSkipped(utils.LogFields{Src: op.Source, Category: op.Category}, "rejected in review")
+	}
+	return nil
+}
+
+// WithCategory returns the operation with its destination in another category folder
+func (op Operation) WithCategory(category string) Operation {
+	name := filepath.Base(op.Destination)
+	root := strings.TrimSuffix(op.Destination, filepath.Join(op.Category, name))
+	op.Destination = filepath.Join(root, category, name)
+	op.Category = category
+	return op
+}
+
+// WithName returns the operation with the file renamed in its destination folder
+func (op Operation) WithName(name string) Operation {
+	op.Destination = filepath.Join(filepath.Dir(op.Destination), name)
+	return op
+}
+
+// Reviewer walks through the planned moves grouped by category and asks the
+// user to accept, reject, re-categorise or rename each file
+type Reviewer struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// NewReviewer creates a reviewer that reads answers from in and writes prompts to out
+func NewReviewer(in io.Reader, out io.Writer) *Reviewer {
+	return &Reviewer{in: bufio.NewReader(in), out: out}
+}
+
+// reviewHelp lists the answers accepted by the reviewer
+const reviewHelp = `  y, Enter     move the file
+  n            leave the file in place
+  c [name]     move the file to another category
+  r [name]     rename the file
+  a            move this file and the rest of the category
+  s            leave this file and the rest of the category in place
+  q            quit without moving anything
+`
+
+// Review asks about every planned move and keeps the accepted operations in
+// the plan. It returns ErrReviewAborted if the user quits or the input ends.
+func (r *Reviewer) Review(plan *Plan) error {
+	// Group the operations by category, keeping the plan's order
+	var categories []string
+	groups := make(map[string][]Operation)
+	for _, op := range plan.Operations {
+		if _, exists := groups[op.Category]; !exists {
+			categories = append(categories, op.Category)
+		}
+		groups[op.Category] = append(groups[op.Category], op)
+	}
+
+	fmt.Fprintf(r.out, "\n🧐 REVIEWING %d PLANNED MOVE(S) (? for help)\n", len(plan.Operations))
+	kept := []Operation{}
+	for _, category := range categories {
+		ops := groups[category]
+		fmt.Fprintf(r.out, "\n📂 %s (%d file(s))\n", category, len(ops))
+
+		acceptRest, skipRest := false, false
+		for i, op := range ops {
+			if acceptRest {
+				kept = append(kept, op)
+				continue
+			}
+			if skipRest {
+				continue
+			}
+
+			keep, rest, err := r.reviewOperation(plan, &op, i+1, len(ops))
+			if err != nil {
+				return err
+			}
+			if keep {
+				kept = append(kept, op)
+			}
+			acceptRest = keep && rest
+			skipRest = !keep && rest
+		}
+	}
+
+	fmt.Fprintf(r.out, "\n✅ %d of %d move(s) accepted\n", len(kept), len(plan.Operations))
+	plan.Operations = kept
+	return nil
+}
+
+// reviewOperation prompts until the user decides about one operation, which
+// may be re-categorised or renamed on the way. It reports whether to keep it
+// and whether the decision applies to the rest of the category.
+func (r *Reviewer) reviewOperation(plan *Plan, op *Operation, index, total int) (keep, rest bool, err error) {
+	for {
+		fmt.Fprintf(r.out, "  [%d/%d] %s (%s) -> %s\n", index, total,
+			r.relative(plan, op.Source), utils.FormatBytes(op.Size), r.relative(plan, op.Destination))
+		fmt.Fprint(r.out, "  Move? [y/n/c/r/a/s/q/?] ")
+
+		answer, err := r.readLine()
+		if err != nil {
+			return false, false, ErrReviewAborted
+		}
+		command, argument, _ := strings.Cut(answer, " ")
+		argument = strings.TrimSpace(argument)
+
+		switch strings.ToLower(command) {
+		case "", "y", "yes":
+			return true, false, nil
+		case "n", "no":
+			return false, false, nil
+		case "a":
+			return true, true, nil
+		case "s":
+			return false, true, nil
+		case "q", "quit":
+			return false, false, ErrReviewAborted
+		case "c":
+			if argument, err = r.ask(argument, "  New category: "); err != nil {
+				return false, false, err
+			}
+			if err := utils.ValidateCategory(argument); err != nil {
+				fmt.Fprintf(r.out, "  Invalid category '%s': %v\n", argument, err)
+				continue
+			}
+			*op = op.WithCategory(argument)
+		case "r":
+			if argument, err = r.ask(argument, "  New name: "); err != nil {
+				return false, false, err
+			}
+			if err := validateFileName(argument); err != nil {
+				fmt.Fprintf(r.out, "  Invalid name '%s': %v\n", argument, err)
+				continue
+			}
+			*op = op.WithName(argument)
+		case "?", "h", "help":
+			fmt.Fprint(r.out, reviewHelp)
+		default:
+			fmt.Fprintf(r.out, "  Unknown answer '%s'\n", answer)
+			fmt.Fprint(r.out, reviewHelp)
+		}
+
+		// A file re-categorised into the folder it is in has nothing to move
+		if op.Destination == op.Source {
+			fmt.Fprintln(r.out, "  Already in place, nothing to move")
+			return false, false, nil
+		}
+	}
+}
+
+// ask returns the given value, or prompts for one if it is empty
+func (r *Reviewer) ask(value, prompt string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+	fmt.Fprint(r.out, prompt)
+	line, err := r.readLine()
+	if err != nil {
+		return "", ErrReviewAborted
+	}
+	return line, nil
+}
+
+// readLine reads one trimmed line of input. A last line without a newline is
+// returned; the end of input is an error.
+func (r *Reviewer) readLine() (string, error) {
+	line, err := r.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// relative shortens a path for display by making it relative to the plan's root
+func (r *Reviewer) relative(plan *Plan, path string) string {
+	if rel, err := filepath.Rel(plan.RootPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// validateFileName checks that a new name is a single path element
+func validateFileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("name must be a single file name")
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package organizer
+
+import (
+	"context"
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ScaffoldConfigName is the file name of the config written by GenerateScaffold
+const ScaffoldConfigName = "organizer.yaml"
+
+// ScaffoldIgnoreName is the file name of the ignore file written by GenerateScaffold
+const ScaffoldIgnoreName = ".organizerignore"
+
+// suggestedCategories proposes categories for common extensions that are not
+// part of the built-in mappings
+var suggestedCategories = map[string]string{
+	".md":       "Documents",
+	".markdown": "Documents",
+	".epub":     "Books",
+	".mobi":     "Books",
+	".log":      "Logs",
+	".bak":      "Backups",
+	".old":      "Backups",
+	".torrent":  "Torrents",
+	".psd":      "Design",
+	".ai":       "Design",
+	".sketch":   "Design",
+	".fig":      "Design",
+	".xd":       "Design",
+	".ttf":      "Fonts",
+	".otf":      "Fonts",
+	".woff":     "Fonts",
+	".woff2":    "Fonts",
+	".heic":     "Images",
+	".avif":     "Images",
+	".raw":      "Images",
+	".sql":      "Code",
+	".sh":       "Code",
+	".bat":      "Code",
+	".ps1":      "Code",
+	".ipynb":    "Code",
+	".srt":      "Subtitles",
+	".vtt":      "Subtitles",
+	".eml":      "Email",
+	".ics":      "Calendar",
+	".vcf":      "Contacts",
+	".opus":     "Audio",
+	".mpg":      "Video",
+	".mpeg":     "Video",
+	".tgz":      "Archives",
+	".zst":      "Archives",
+	".pkg":      "Executables",
+	".appimage": "Executables",
+}
+
+// partialDownloadExtensions are files still being written by browsers and tools,
+// which should be ignored rather than organized
+var partialDownloadExtensions = map[string]bool{
+	".part":       true,
+	".crdownload": true,
+	".download":   true,
+	".partial":    true,
+	".tmp":        true,
+}
+
+// wellKnownIgnoredDirs are directories that should never be organized
+var wellKnownIgnoredDirs = []string{".git", ".svn", ".hg", "node_modules", ".venv", "__pycache__", ".idea", ".vscode"}
+
+// Scaffold holds the generated contents of a starter config and ignore file
+type Scaffold struct {
+	// Config is a commented YAML configuration
+	Config string
+	// Ignore is the content of a .organizerignore file
+	Ignore string
+	// FilesScanned is the number of files found while scanning
+	FilesScanned int
+	// UnknownExtensions lists the extensions the built-in mappings do not know
+	UnknownExtensions []string
+}
+
+// extensionStats counts the files found for an unknown extension
+type extensionStats struct {
+	extension string
+	count     int
+	example   string
+}
+
+// GenerateScaffold scans a directory and generates a commented config and ignore
+// file tailored to its contents. Unknown extensions found during the scan get a
+// proposed mapping where a sensible category is known. The scan stops once ctx
+// is done.
+func GenerateScaffold(ctx context.Context, rootPath string) (*Scaffold, error) {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %v", err)
+	}
+
+	// Don't descend into tool directories or count the organizer's own files
+	ignoreManager := utils.NewIgnoreManager(absRoot)
+	for _, dir := range wellKnownIgnoredDirs {
+		ignoreManager.AddPatterns([]string{dir + "/"})
+	}
+	ignoreManager.AddPatterns([]string{"/" + ScaffoldConfigName, "/" + ScaffoldIgnoreName, "/organizer.log"})
+
+	categories, err := ScanFilesWithConfig(ctx, absRoot, nil, ignoreManager)
+	if err != nil {
+		return nil, err
+	}
+
+	scaffold := &Scaffold{}
+	unknown := make(map[string]*extensionStats)
+	partials := make(map[string]bool)
+	for category, files := range categories {
+		scaffold.FilesScanned += len(files)
+		if category != "Unknown" {
+			continue
+		}
+
+		for _, file := range files {
+			ext := strings.ToLower(filepath.Ext(file))
+			if partialDownloadExtensions[ext] {
+				partials[ext] = true
+				continue
+			}
+			if unknown[ext] == nil {
+				unknown[ext] = &extensionStats{extension: ext, example: filepath.Base(file)}
+			}
+			unknown[ext].count++
+		}
+	}
+
+	stats := make([]*extensionStats, 0, len(unknown))
+	for _, stat := range unknown {
+		stats = append(stats, stat)
+		scaffold.UnknownExtensions = append(scaffold.UnknownExtensions, stat.extension)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].count != stats[j].count {
+			return stats[i].count > stats[j].count
+		}
+		return stats[i].extension < stats[j].extension
+	})
+	sort.Strings(scaffold.UnknownExtensions)
+
+	scaffold.Config = renderScaffoldConfig(absRoot, categories, stats, scaffold.FilesScanned)
+	scaffold.Ignore = renderScaffoldIgnore(absRoot, partials)
+	return scaffold, nil
+}
+
+// renderScaffoldConfig writes the commented YAML config
+func renderScaffoldConfig(absRoot string, categories map[string][]string, stats []*extensionStats, filesScanned int) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# go-file-organizer configuration for %s\n", absRoot)
+	fmt.Fprintf(&b, "# Generated by `go-file-organizer init` on %s after scanning %d files.\n", time.Now().Format("2006-01-02"), filesScanned)
+	b.WriteString("#\n")
+	b.WriteString("# Preview and run with:\n")
+	fmt.Fprintf(&b, "#   go-file-organizer --config %s --dry-run\n", strconv.Quote(filepath.Join(absRoot, ScaffoldConfigName)))
+	fmt.Fprintf(&b, "#   go-file-organizer --config %s\n", strconv.Quote(filepath.Join(absRoot, ScaffoldConfigName)))
+	b.WriteString("#\n")
+	b.WriteString("# Files found per category with the built-in mappings:\n")
+
+	names := make([]string, 0, len(categories))
+	for category := range categories {
+		names = append(names, category)
+	}
+	sort.Strings(names)
+	for _, category := range names {
+		fmt.Fprintf(&b, "#   %-14s %d\n", category+":", len(categories[category]))
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "description: %s\n", strconv.Quote("Organizer settings for "+filepath.Base(absRoot)))
+	fmt.Fprintf(&b, "path: %s\n", strconv.Quote(absRoot))
+	fmt.Fprintf(&b, "ignoreFile: %s\n", strconv.Quote(filepath.Join(absRoot, ScaffoldIgnoreName)))
+	b.WriteString("\n")
+
+	b.WriteString("# Mappings for extensions found here that the built-in table does not know.\n")
+	b.WriteString("# Review each one: change the category, or delete the line to leave those files alone.\n")
+	b.WriteString("# Commented entries had no suggestion; pick a category and uncomment them.\n")
+	// A key whose entries are all commented out would parse as null
+	suggested := false
+	for _, stat := range stats {
+		if _, exists := suggestedCategories[stat.extension]; exists {
+			suggested = true
+		}
+	}
+	if suggested {
+		b.WriteString("customMappings:\n")
+	} else {
+		b.WriteString("customMappings: {}\n")
+	}
+	for _, stat := range stats {
+		fmt.Fprintf(&b, "  # %d file(s), e.g. %s\n", stat.count, strconv.Quote(stat.example))
+		if category, exists := suggestedCategories[stat.extension]; exists {
+			fmt.Fprintf(&b, "  %s: %s\n", stat.extension, category)
+		} else {
+			fmt.Fprintf(&b, "  # %s: Misc\n", stat.extension)
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString("# Profiles keep different rules for different folders; select one with --profile.\n")
+	b.WriteString("# profiles:\n")
+	b.WriteString("#   screenshots:\n")
+	b.WriteString("#     destination: Sorted\n")
+	b.WriteString("#     conflictPolicy: rename\n")
+	b.WriteString("#     rules:\n")
+	b.WriteString("#       - pattern: \"Screenshot*\"\n")
+	b.WriteString("#         category: Screenshots\n")
+
+	return b.String()
+}
+
+// renderScaffoldIgnore writes the .organizerignore content
+func renderScaffoldIgnore(absRoot string, partials map[string]bool) string {
+	var b strings.Builder
+
+	b.WriteString("# .organizerignore - files and directories to leave in place\n")
+	fmt.Fprintf(&b, "# Generated by `go-file-organizer init` for %s\n", absRoot)
+	b.WriteString("\n")
+
+	b.WriteString("# The organizer's own files\n")
+	fmt.Fprintf(&b, "/%s\n", ScaffoldConfigName)
+	fmt.Fprintf(&b, "/%s\n", ScaffoldIgnoreName)
+	b.WriteString("/organizer.log\n")
+	b.WriteString("\n")
+
+	b.WriteString("# System files\n")
+	b.WriteString(".DS_Store\n")
+	b.WriteString("Thumbs.db\n")
+	b.WriteString("desktop.ini\n")
+
+	var dirs []string
+	for _, dir := range wellKnownIgnoredDirs {
+		if info, err := os.Stat(filepath.Join(absRoot, dir)); err == nil && info.IsDir() {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) > 0 {
+		b.WriteString("\n# Tool and version control directories found here\n")
+		for _, dir := range dirs {
+			fmt.Fprintf(&b, "%s/\n", dir)
+		}
+	}
+
+	if len(partials) > 0 {
+		exts := make([]string, 0, len(partials))
+		for ext := range partials {
+			exts = append(exts, ext)
+		}
+		sort.Strings(exts)
+
+		b.WriteString("\n# Downloads and temporary files that are still being written\n")
+		for _, ext := range exts {
+			fmt.Fprintf(&b, "*%s\n", ext)
+		}
+	}
+
+	b.WriteString("\n# Add your own patterns below\n")
+	return b.String()
+}
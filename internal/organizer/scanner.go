@@ -3,8 +3,11 @@
 package organizer
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"go-file-organizer/internal/utils"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -120,31 +123,53 @@ var extensionCategories = map[string]string{
 // It uses the default extension mappings and does not apply any ignore rules.
 // Returns a map where keys are category names and values are slices of file paths.
 func ScanFiles(rootPath string) (map[string][]string, error) {
-	return ScanFilesWithConfig(rootPath, nil, nil)
+	return ScanFilesWithConfig(context.Background(), rootPath, nil, nil)
 }
 
 // ScanFilesWithConfig recursively scans a directory with custom configuration and ignore rules.
 // Parameters:
+//   - ctx: Stops the scan once done, with an error wrapping ErrInterrupted
 //   - rootPath: The directory to scan
 //   - extensionMapping: Custom extension-to-category mappings (nil to use defaults)
 //   - ignoreManager: Manager for ignore patterns (nil to ignore no files)
 //
 // Returns a map where keys are category names and values are slices of file paths.
-func ScanFilesWithConfig(rootPath string, extensionMapping *utils.ExtensionMapping, ignoreManager *utils.IgnoreManager) (map[string][]string, error) {
-	// Initialize the result map
-	categories := make(map[string][]string)
+func ScanFilesWithConfig(ctx context.Context, rootPath string, extensionMapping *utils.ExtensionMapping, ignoreManager *utils.IgnoreManager) (map[string][]string, error) {
+	result, err := scanFiles(ctx, OSFS{}, rootPath, extensionClassifier(extensionMapping), ignoreManager, nil, nil, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	return result.categories, nil
+}
+
+// scanFiles implements ScanFilesWithConfig, classifying every file with the
+// chain of classifiers. Ignored entries and inaccessible paths are recorded in
+// the summary and the files found reported to the observer, if given.
+// Warnings are written to output.
+func scanFiles(ctx context.Context, fsys FS, rootPath string, classifiers []Classifier, ignoreManager *utils.IgnoreManager, summary *utils.Summary, observer Observer, output io.Writer) (*scanResult, error) {
+	// Initialize the result
+	result := newScanResult()
+	found := 0
 
 	// Check if the root path exists and is accessible
-	if _, err := os.Stat(rootPath); os.IsNotExist(err) {
+	if _, err := fsys.Stat(rootPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("directory does not exist: %s", rootPath)
 	}
 
 	// Walk through the directory tree
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+	err := fsys.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+		// Stop as soon as the run is cancelled
+		if err := interrupted(ctx); err != nil {
+			return err
+		}
+
 		// Handle errors during walk
 		if err != nil {
 			// Log the error but continue walking
-			fmt.Printf("Warning: Could not access %s: %v\n", path, err)
+			fmt.Fprintf(output, "Warning: Could not access %s: %v\n", path, err)
+			if summary != nil {
+				summary.RecordFailure("Scan", path, err)
+			}
 			return nil
 		}
 
@@ -152,49 +177,178 @@ func ScanFilesWithConfig(rootPath string, extensionMapping *utils.ExtensionMappi
 		if info.IsDir() {
 			// Check if this directory should be ignored
 			if ignoreManager != nil && ignoreManager.ShouldIgnore(path) {
+				if summary != nil {
+					summary.DirectoriesIgnored++
+				}
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
+		found++
+		if observer != nil {
+			observer.ScanProgress(path, found)
+		}
+
 		// Check if this file should be ignored
 		if ignoreManager != nil && ignoreManager.ShouldIgnore(path) {
+			if summary != nil {
+				summary.SkippedByIgnore++
+			}
+			if observer != nil {
+				observer.Decided(Decision{Path: path, Reason: ReasonIgnored})
+			}
 			return nil
 		}
 
-		// Get the file extension (case-insensitive)
-		ext := strings.ToLower(filepath.Ext(info.Name()))
+		// Add the file path to the category of the first classifier that recognizes it
+		result.classify(classifiers, NewCandidate(fsys, path, info), summary, output)
 
-		// Determine the category
-		var category string
-		var exists bool
+		return nil
+	})
 
-		if extensionMapping != nil {
-			category, exists = extensionMapping.GetMapping(ext)
-		} else {
-			category, exists = extensionCategories[ext]
+	if errors.Is(err, ErrInterrupted) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error walking directory: %v", err)
+	}
+
+	return result, nil
+}
+
+// ScanFileList categorizes the listed files, e.g. the output of find, like
+// ScanFilesWithConfig does for every file under rootPath. Relative names are
+// resolved against rootPath; all names must lead inside it. The scan stops
+// once ctx is done.
+func ScanFileList(ctx context.Context, rootPath string, files []string, extensionMapping *utils.ExtensionMapping, ignoreManager *utils.IgnoreManager) (map[string][]string, error) {
+	result, err := scanFileList(ctx, OSFS{}, rootPath, files, extensionClassifier(extensionMapping), ignoreManager, nil, nil, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	return result.categories, nil
+}
+
+// scanFileList implements ScanFileList. Ignore rules apply to the listed files
+// and their parent directories. Files outside rootPath or that cannot be read
+// are recorded as failures in the summary if one is given; directories and
+// repeated names are skipped.
+func scanFileList(ctx context.Context, fsys FS, rootPath string, files []string, classifiers []Classifier, ignoreManager *utils.IgnoreManager, summary *utils.Summary, observer Observer, output io.Writer) (*scanResult, error) {
+	result := newScanResult()
+
+	if _, err := fsys.Stat(rootPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", rootPath)
+	}
+	root := filepath.Clean(rootPath)
+
+	// fail reports a listed file that cannot be organized
+	fail := func(path string, err error) {
+		fmt.Fprintf(output, "Warning: Could not access %s: %v\n", path, err)
+		if summary != nil {
+			summary.RecordFailure("Scan", path, err)
 		}
+	}
 
-		if !exists {
-			// Handle files with no extension or unknown extensions
-			if ext == "" {
-				category = "No Extension"
-			} else {
-				category = "Unknown"
+	seen := make(map[string]bool, len(files))
+	found := 0
+	for _, file := range files {
+		if err := interrupted(ctx); err != nil {
+			return nil, err
+		}
+
+		// Refer to the file through rootPath, as a walk of it would. Absolute
+		// names can only lead inside an absolute rootPath.
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			fail(file, fmt.Errorf("not inside %s", rootPath))
+			continue
+		}
+		path = filepath.Join(rootPath, rel)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		info, err := fsys.Lstat(path)
+		if err != nil {
+			fail(path, err)
+			continue
+		}
+		if info.IsDir() {
+			continue
+		}
+		found++
+		if observer != nil {
+			observer.ScanProgress(path, found)
+		}
+
+		if ignoreManager != nil {
+			if _, ignored := ignoreManager.MatchingPatternOrParent(path); ignored {
+				if summary != nil {
+					summary.SkippedByIgnore++
+				}
+				if observer != nil {
+					observer.Decided(Decision{Path: path, Reason: ReasonIgnored})
+				}
+				continue
 			}
 		}
 
-		// Add the file path to the appropriate category
-		categories[category] = append(categories[category], path)
+		result.classify(classifiers, NewCandidate(fsys, path, info), summary, output)
+	}
 
-		return nil
-	})
+	return result, nil
+}
+
+// scan categorizes the files to organize: the listed Files, or every file
+// under RootPath
+func (o Options) scan(ctx context.Context, summary *utils.Summary) (*scanResult, error) {
+	if o.Files != nil {
+		return scanFileList(ctx, o.filesystem(), o.RootPath, o.Files, o.classifiers(), o.IgnoreManager, summary, o.Observer, o.output())
+	}
+	return scanFiles(ctx, o.filesystem(), o.RootPath, o.classifiers(), o.IgnoreManager, summary, o.Observer, o.output())
+}
+
+// scanResult is what a scan found: the files per category, and the metadata
+// that classifiers attached to some of them
+type scanResult struct {
+	categories map[string][]string
+	metadata   map[string]map[string]string
+}
 
+// newScanResult returns an empty scan result
+func newScanResult() *scanResult {
+	return &scanResult{
+		categories: make(map[string][]string),
+		metadata:   make(map[string]map[string]string),
+	}
+}
+
+// classify adds a file to the category the chain of classifiers gives it. A
+// file with an invalid category is recorded as a failure in the summary, if
+// one is given, and left out.
+func (r *scanResult) classify(classifiers []Classifier, file *Candidate, summary *utils.Summary, output io.Writer) {
+	classification, err := classify(classifiers, file)
 	if err != nil {
-		return nil, fmt.Errorf("error walking directory: %v", err)
+		fmt.Fprintf(output, "Warning: Could not classify %s: %v\n", file.Path, err)
+		if summary != nil {
+			summary.RecordFailure("Classify", file.Path, err)
+		}
+		return
+	}
+	r.categories[classification.Category] = append(r.categories[classification.Category], file.Path)
+	if len(classification.Metadata) > 0 {
+		r.metadata[file.Path] = classification.Metadata
 	}
+}
 
-	return categories, nil
+// extensionClassifier returns the chain that only looks up extensions
+func extensionClassifier(extensionMapping *utils.ExtensionMapping) []Classifier {
+	return []Classifier{ExtensionClassifier{Mapping: extensionMapping}}
 }
 
 // GetDefaultExtensionCategories returns a copy of the default extension mappings.
@@ -0,0 +1,56 @@
+package organizer
+
+import (
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"io"
+	"strings"
+	"time"
+)
+
+// shellQuote quotes a string for a POSIX shell. Everything inside single quotes
+// is literal, so each single quote closes the quoting, adds an escaped quote and
+// reopens it.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// scriptWriter writes the moves of a dry run as a POSIX shell script
+type scriptWriter struct {
+	w       io.Writer
+	moveCmd string
+	err     error
+}
+
+// newScriptWriter writes the script header. Existing destinations are left
+// alone with mv -n, unless the conflict policy is to overwrite them.
+func newScriptWriter(w io.Writer, rootPath string, policy utils.ConflictPolicy) *scriptWriter {
+	sw := &scriptWriter{w: w, moveCmd: "mv -n --"}
+	if policy == utils.ConflictOverwrite {
+		sw.moveCmd = "mv -f --"
+	}
+	sw.printf("#!/bin/sh\n")
+	sw.printf("# Generated by go-file-organizer on %s\n", time.Now().Format(time.RFC3339))
+	sw.printf("# Organizes: %s\n", strings.ReplaceAll(rootPath, "\n", "?"))
+	sw.printf("# Review the commands below, then run this script with sh.\n")
+	sw.printf("set -eu\n\n")
+	return sw
+}
+
+// Mkdir adds a command creating a category folder
+func (sw *scriptWriter) Mkdir(folder string) {
+	sw.printf("mkdir -p -- %s\n", shellQuote(folder))
+}
+
+// Move adds a command moving a file
+func (sw *scriptWriter) Move(source, destination string) {
+	sw.printf("%s %s %s\n", sw.moveCmd, shellQuote(source), shellQuote(destination))
+}
+
+// printf writes to the script, remembering the first error
+func (sw *scriptWriter) printf(format string, args ...interface{}) {
+	if sw.err != nil {
+		return
+	}
+	_, sw.err = fmt.Fprintf(sw.w, format, args...)
+}
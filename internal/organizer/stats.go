@@ -0,0 +1,137 @@
+package organizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-file-organizer/internal/utils"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Stats describes the files of a directory and how much of it is organized
+type Stats struct {
+	RootPath string `json:"rootPath"`
+	// Files and Bytes count every scanned file
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+	// Categories holds the files and bytes per target category, wherever the
+	// files currently are
+	Categories map[string]*utils.CategoryStats `json:"categories"`
+	// Extensions counts the scanned files per lower-case extension ("" for none)
+	Extensions map[string]int `json:"extensions"`
+	// Organized counts files already in their category folder
+	Organized int `json:"organized"`
+	// Pending counts files an organize run would move
+	Pending int `json:"pending"`
+	// LeftInPlace counts files whose category is not organized
+	LeftInPlace int `json:"leftInPlace"`
+	// Ignored counts files matched by an ignore pattern
+	Ignored int `json:"ignored"`
+	// Failures lists the paths that could not be read
+	Failures []utils.Failure `json:"failures"`
+}
+
+// CollectStats scans the root directory with the given options and reports the
+// files per category and extension, without moving anything. Once ctx is done
+// the scan stops with an error wrapping ErrInterrupted.
+func CollectStats(ctx context.Context, opts Options) (*Stats, error) {
+	summary := utils.NewSummary()
+	scanned, err := opts.scan(ctx, summary)
+	if errors.Is(err, ErrInterrupted) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan files: %v", err)
+	}
+
+	stats := &Stats{
+		RootPath:   opts.RootPath,
+		Categories: make(map[string]*utils.CategoryStats),
+		Ignored:    summary.SkippedByIgnore,
+	}
+
+	destinationRoot := opts.destinationRoot()
+	for category, files := range scanned.categories {
+		for _, filePath := range files {
+			info, err := opts.filesystem().Stat(filePath)
+			if err != nil {
+				summary.RecordFailure("Scan", filePath, err)
+				continue
+			}
+			summary.RecordScanned(filePath)
+			stats.Files++
+			stats.Bytes += info.Size()
+
+			target, ok := opts.resolveCategory(filePath, category)
+			totals, exists := stats.Categories[target]
+			if !exists {
+				totals = &utils.CategoryStats{}
+				stats.Categories[target] = totals
+			}
+			totals.Files++
+			totals.Bytes += info.Size()
+
+			switch {
+			case !ok:
+				stats.LeftInPlace++
+			case filepath.Dir(filePath) == filepath.Join(destinationRoot, target):
+				stats.Organized++
+			default:
+				stats.Pending++
+			}
+		}
+	}
+	stats.Extensions = summary.Extensions
+	stats.Failures = append([]utils.Failure{}, summary.Failures...)
+
+	return stats, nil
+}
+
+// FprintStats writes a formatted report of directory statistics to w
+func FprintStats(w io.Writer, stats *Stats) {
+	separator := strings.Repeat("=", 50)
+
+	fmt.Fprintln(w, "\n"+separator)
+	fmt.Fprintln(w, "📊 DIRECTORY STATISTICS")
+	fmt.Fprintln(w, separator)
+	fmt.Fprintf(w, "📄  Files: %d (%s)\n", stats.Files, utils.FormatBytes(stats.Bytes))
+	fmt.Fprintf(w, "👌  Already organized: %d\n", stats.Organized)
+	fmt.Fprintf(w, "🔀  Would be moved: %d\n", stats.Pending)
+	fmt.Fprintf(w, "🚫  Left in place: %d\n", stats.LeftInPlace)
+	if stats.Ignored > 0 {
+		fmt.Fprintf(w, "🙈  Ignored: %d\n", stats.Ignored)
+	}
+	if len(stats.Failures) > 0 {
+		fmt.Fprintf(w, "❌  Unreadable: %d\n", len(stats.Failures))
+	}
+
+	summary := &utils.Summary{Categories: stats.Categories, Extensions: stats.Extensions}
+	if len(stats.Categories) > 0 {
+		fmt.Fprintln(w, separator)
+		fmt.Fprintln(w, "📂 By category:")
+		for _, name := range summary.CategoryNames() {
+			totals := stats.Categories[name]
+			fmt.Fprintf(w, "    %-20s %6d files %12s\n", name, totals.Files, utils.FormatBytes(totals.Bytes))
+		}
+	}
+
+	if extensions := summary.TopExtensions(); len(extensions) > 0 {
+		fmt.Fprintln(w, separator)
+		fmt.Fprintln(w, "🏷️  By extension:")
+		for i, ext := range extensions {
+			if i == maxSummaryExtensions {
+				fmt.Fprintf(w, "    ... and %d more\n", len(extensions)-maxSummaryExtensions)
+				break
+			}
+			name := ext
+			if name == "" {
+				name = "(none)"
+			}
+			fmt.Fprintf(w, "    %-20s %6d files\n", name, stats.Extensions[ext])
+		}
+	}
+
+	fmt.Fprintln(w, separator)
+}
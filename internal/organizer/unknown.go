@@ -0,0 +1,104 @@
+package organizer
+
+import (
+	"go-file-organizer/internal/utils"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMiscFolder is the folder used by the "move" unknown file policy
+const DefaultMiscFolder = "Misc"
+
+// DefaultOtherFolder is the parent folder used by the "group" unknown file policy
+const DefaultOtherFolder = "Other"
+
+// sniffedCategories maps MIME types detected from file content to categories.
+// Entries ending in "/" match every subtype.
+var sniffedCategories = map[string]string{
+	"image/":                       "Images",
+	"audio/":                       "Audio",
+	"video/":                       "Video",
+	"application/ogg":              "Audio",
+	"application/pdf":              "Documents",
+	"application/postscript":       "Documents",
+	"application/rtf":              "Documents",
+	"text/plain":                   "Documents",
+	"text/html":                    "Code",
+	"text/xml":                     "Code",
+	"application/json":             "Code",
+	"application/zip":              "Archives",
+	"application/x-gzip":           "Archives",
+	"application/x-rar-compressed": "Archives",
+	"application/x-7z-compressed":  "Archives",
+	"application/wasm":             "Code",
+}
+
+// isUncategorized reports whether a category is one the scanner assigns to files
+// without a known mapping
+func isUncategorized(category string) bool {
+	return category == "Unknown" || category == "No Extension"
+}
+
+// sniffHeader detects a category from the first bytes of a file
+func sniffHeader(header []byte) (string, bool) {
+	if len(header) == 0 {
+		return "", false
+	}
+
+	// Drop parameters such as "; charset=utf-8"
+	contentType := http.DetectContentType(header)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	if category, exists := sniffedCategories[contentType]; exists {
+		return category, true
+	}
+	if i := strings.Index(contentType, "/"); i >= 0 {
+		if category, exists := sniffedCategories[contentType[:i+1]]; exists {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// shouldSkip determines if files of a category are left in place because it is
+// listed in SkipCategories
+func (o Options) shouldSkip(category string) bool {
+	for _, skip := range o.SkipCategories {
+		if strings.EqualFold(skip, category) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveCategory returns the folder a scanned file should be moved to, relative to
+// the destination root. Files no classifier recognized are handled by the
+// unknown file policy. It returns false if the file should be left in place.
+func (o Options) resolveCategory(path, category string) (string, bool) {
+	if !isUncategorized(category) {
+		return category, !o.shouldSkip(category)
+	}
+
+	switch o.UnknownPolicy {
+	case utils.UnknownMove:
+		folder := o.UnknownFolder
+		if folder == "" {
+			folder = DefaultMiscFolder
+		}
+		return folder, !o.shouldSkip(folder)
+	case utils.UnknownGroup:
+		folder := o.UnknownFolder
+		if folder == "" {
+			folder = DefaultOtherFolder
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext != "" {
+			return filepath.Join(folder, ext), !o.shouldSkip(folder)
+		}
+		return filepath.Join(folder, category), !o.shouldSkip(folder)
+	default:
+		return category, false
+	}
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"unicode/utf8"
+)
+
+// KeyType identifies a key press read from the terminal
+type KeyType int
+
+const (
+	// KeyRune is a printable character, stored in Key.Rune
+	KeyRune KeyType = iota
+	// KeyUp and KeyDown are the arrow keys
+	KeyUp
+	KeyDown
+	// KeyPageUp and KeyPageDown scroll by a page
+	KeyPageUp
+	KeyPageDown
+	// KeyHome and KeyEnd jump to the first and last file
+	KeyHome
+	KeyEnd
+	// KeyEnter is Return or a line feed
+	KeyEnter
+	// KeyBackspace is Backspace or Ctrl+H
+	KeyBackspace
+	// KeyEscape is a lone escape byte
+	KeyEscape
+	// KeyCtrlC is Ctrl+C, which raw mode delivers as a byte instead of a signal
+	KeyCtrlC
+	// KeyCtrlU is Ctrl+U, which clears the answer to a prompt
+	KeyCtrlU
+)
+
+// Key is a single key press
+type Key struct {
+	Type KeyType
+	Rune rune
+}
+
+// escapeSequences maps the CSI and SS3 sequences sent by common terminals to keys
+var escapeSequences = map[string]KeyType{
+	"[A":  KeyUp,
+	"[B":  KeyDown,
+	"OA":  KeyUp,
+	"OB":  KeyDown,
+	"[5~": KeyPageUp,
+	"[6~": KeyPageDown,
+	"[H":  KeyHome,
+	"[F":  KeyEnd,
+	"OH":  KeyHome,
+	"OF":  KeyEnd,
+	"[1~": KeyHome,
+	"[4~": KeyEnd,
+}
+
+// ParseKeys decodes the bytes read from a terminal in raw mode into key
+// presses. Unsupported escape sequences and control characters are dropped.
+func ParseKeys(data []byte) []Key {
+	var keys []Key
+	for len(data) > 0 {
+		b := data[0]
+		switch {
+		case b == 0x1b:
+			length, keyType, ok := parseEscape(data)
+			if ok {
+				keys = append(keys, Key{Type: keyType})
+			}
+			data = data[length:]
+			continue
+		case b == '\r' || b == '\n':
+			keys = append(keys, Key{Type: KeyEnter})
+		case b == 0x7f || b == 0x08:
+			keys = append(keys, Key{Type: KeyBackspace})
+		case b == 0x03:
+			keys = append(keys, Key{Type: KeyCtrlC})
+		case b == 0x15:
+			keys = append(keys, Key{Type: KeyCtrlU})
+		case b < 0x20:
+			// Other control characters have no action
+		default:
+			r, size := utf8.DecodeRune(data)
+			if r != utf8.RuneError {
+				keys = append(keys, Key{Type: KeyRune, Rune: r})
+			}
+			data = data[size:]
+			continue
+		}
+		data = data[1:]
+	}
+	return keys
+}
+
+// parseEscape decodes the escape sequence at the start of data and returns
+// its length and key. A lone escape byte is the Escape key.
+func parseEscape(data []byte) (int, KeyType, bool) {
+	if len(data) == 1 || (data[1] != '[' && data[1] != 'O') {
+		return 1, KeyEscape, true
+	}
+
+	// A sequence ends with its first byte in the range @ to ~ after the prefix
+	end := 2
+	for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+		end++
+	}
+	if end == len(data) {
+		return len(data), 0, false
+	}
+	keyType, ok := escapeSequences[string(data[1:end+1])]
+	return end + 1, keyType, ok
+}
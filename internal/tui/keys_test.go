@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeys(t *testing.T) {
+	keys := ParseKeys([]byte("j\x1b[A\x1b[B\x1bOA\x1b[5~\x1b[6~\r\x7f\x03\x15é\x1b"))
+	assert.Equal(t, []Key{
+		{Type: KeyRune, Rune: 'j'},
+		{Type: KeyUp},
+		{Type: KeyDown},
+		{Type: KeyUp},
+		{Type: KeyPageUp},
+		{Type: KeyPageDown},
+		{Type: KeyEnter},
+		{Type: KeyBackspace},
+		{Type: KeyCtrlC},
+		{Type: KeyCtrlU},
+		{Type: KeyRune, Rune: 'é'},
+		{Type: KeyEscape},
+	}, keys)
+}
+
+func TestParseKeysDropsUnknownSequences(t *testing.T) {
+	// F5, Ctrl+arrow and a tab have no action
+	keys := ParseKeys([]byte("\x1b[15~a\x1b[1;5C\tb"))
+	assert.Equal(t, []Key{{Type: KeyRune, Rune: 'a'}, {Type: KeyRune, Rune: 'b'}}, keys)
+
+	// An incomplete sequence at the end of a read is dropped
+	assert.Empty(t, ParseKeys([]byte("\x1b[1;")))
+}
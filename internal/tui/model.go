@@ -0,0 +1,363 @@
+// Package tui provides a full-screen terminal interface for browsing and
+// adjusting the planned moves before they are applied.
+package tui
+
+import (
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Backend rebuilds the plan and persists the changes made in the interface
+type Backend interface {
+	// Plan scans the directory again and returns the moves it would make
+	Plan() (*organizer.Plan, error)
+	// AddMapping maps an extension to a category for this run and saves it,
+	// returning where it was saved
+	AddMapping(ext, category string) (string, error)
+	// AddIgnorePattern ignores a pattern for this run and saves it, returning
+	// where it was saved
+	AddIgnorePattern(pattern string) (string, error)
+}
+
+// promptKind identifies the question asked on the bottom line
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptCategory
+	promptIgnore
+	promptMapping
+)
+
+// helpLine lists the keys available while browsing
+const helpLine = "↑/↓ move  c category  x skip  i ignore  m map  a apply  q quit"
+
+// Model is the state of the interface: the plan, the changes made to it and
+// the prompt being answered. It is driven by HandleKey and drawn by Render.
+type Model struct {
+	backend Backend
+	plan    *organizer.Plan
+	files   []organizer.Operation // planned operations in source tree order
+
+	// Changes made by the user, keyed by source path; they survive rebuilds
+	overrides map[string]string
+	skipped   map[string]bool
+
+	cursor  int
+	page    int // height of the panes at the last render
+	prompt  promptKind
+	input   []rune
+	message string
+
+	done  bool
+	apply bool
+}
+
+// NewModel creates a model showing the given plan
+func NewModel(plan *organizer.Plan, backend Backend) *Model {
+	m := &Model{
+		backend:   backend,
+		overrides: make(map[string]string),
+		skipped:   make(map[string]bool),
+		page:      10,
+	}
+	m.setPlan(plan)
+	return m
+}
+
+// Done reports whether the user applied the plan or quit
+func (m *Model) Done() bool {
+	return m.done
+}
+
+// Result returns the operations to make, in plan order, and whether the user
+// chose to apply them rather than quit
+func (m *Model) Result() ([]organizer.Operation, bool) {
+	ops := []organizer.Operation{}
+	for _, op := range m.plan.Operations {
+		if !m.inPlace(op) {
+			ops = append(ops, m.effective(op))
+		}
+	}
+	return ops, m.apply
+}
+
+// HandleKey applies one key press
+func (m *Model) HandleKey(key Key) {
+	if key.Type == KeyCtrlC {
+		m.done = true
+		return
+	}
+	if m.prompt != promptNone {
+		m.handlePromptKey(key)
+		return
+	}
+
+	m.message = ""
+	switch key.Type {
+	case KeyUp:
+		m.move(-1)
+	case KeyDown:
+		m.move(1)
+	case KeyPageUp:
+		m.move(-m.page)
+	case KeyPageDown:
+		m.move(m.page)
+	case KeyHome:
+		m.move(-len(m.files))
+	case KeyEnd:
+		m.move(len(m.files))
+	case KeyEscape:
+		m.done = true
+	case KeyRune:
+		m.handleCommand(key.Rune)
+	}
+}
+
+// handleCommand runs the action bound to a letter while browsing
+func (m *Model) handleCommand(r rune) {
+	op, selected := m.selected()
+	switch r {
+	case 'k':
+		m.move(-1)
+	case 'j':
+		m.move(1)
+	case 'a':
+		m.done, m.apply = true, true
+	case 'q':
+		m.done = true
+	case 'c':
+		if !selected {
+			m.message = "No planned moves"
+			return
+		}
+		m.startPrompt(promptCategory, m.effective(op).Category)
+	case 'x':
+		if !selected {
+			m.message = "No planned moves"
+			return
+		}
+		m.skipped[op.Source] = !m.skipped[op.Source]
+		if m.skipped[op.Source] {
+			m.message = fmt.Sprintf("%s will be left in place", filepath.Base(op.Source))
+		} else {
+			delete(m.skipped, op.Source)
+			m.message = fmt.Sprintf("%s will be moved", filepath.Base(op.Source))
+		}
+	case 'i':
+		suggestion := ""
+		if selected {
+			suggestion = suggestIgnorePattern(op.Source)
+		}
+		m.startPrompt(promptIgnore, suggestion)
+	case 'm':
+		suggestion := ""
+		if ext := strings.ToLower(filepath.Ext(op.Source)); selected && ext != "" {
+			suggestion = ext + "=" + m.effective(op).Category
+		}
+		m.startPrompt(promptMapping, suggestion)
+	}
+}
+
+// startPrompt asks a question on the bottom line with a suggested answer
+func (m *Model) startPrompt(kind promptKind, suggestion string) {
+	m.prompt = kind
+	m.input = []rune(suggestion)
+	m.message = "Enter to confirm, Esc to cancel, Ctrl+U to clear"
+}
+
+// handlePromptKey edits or submits the answer to the current prompt
+func (m *Model) handlePromptKey(key Key) {
+	switch key.Type {
+	case KeyEscape:
+		m.prompt = promptNone
+		m.message = "Cancelled"
+	case KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case KeyCtrlU:
+		m.input = nil
+	case KeyRune:
+		m.input = append(m.input, key.Rune)
+	case KeyEnter:
+		m.submitPrompt(strings.TrimSpace(string(m.input)))
+	}
+}
+
+// submitPrompt acts on an answer. Invalid answers keep the prompt open so
+// they can be corrected.
+func (m *Model) submitPrompt(answer string) {
+	kind := m.prompt
+	if answer == "" {
+		m.prompt = promptNone
+		m.message = "Cancelled"
+		return
+	}
+
+	switch kind {
+	case promptCategory:
+		if err := utils.ValidateCategory(answer); err != nil {
+			m.message = fmt.Sprintf("Invalid category '%s': %v", answer, err)
+			return
+		}
+		m.prompt = promptNone
+		op, _ := m.selected()
+		if answer == op.Category {
+			delete(m.overrides, op.Source)
+		} else {
+			m.overrides[op.Source] = answer
+		}
+		delete(m.skipped, op.Source)
+		if m.inPlace(op) {
+			m.message = fmt.Sprintf("%s is already in %s, nothing to move", filepath.Base(op.Source), answer)
+		} else {
+			m.message = fmt.Sprintf("%s will be moved to %s", filepath.Base(op.Source), answer)
+		}
+
+	case promptIgnore:
+		m.prompt = promptNone
+		where, err := m.backend.AddIgnorePattern(answer)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		m.message = fmt.Sprintf("Ignoring %s (saved to %s)", answer, where)
+		m.reload()
+
+	case promptMapping:
+		ext, category, found := strings.Cut(answer, "=")
+		if !found {
+			m.message = fmt.Sprintf("Invalid mapping '%s', expected '.ext=Category'", answer)
+			return
+		}
+		ext, category = strings.ToLower(strings.TrimSpace(ext)), strings.TrimSpace(category)
+		m.prompt = promptNone
+		where, err := m.backend.AddMapping(ext, category)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		m.message = fmt.Sprintf("Mapped %s to %s (saved to %s)", ext, category, where)
+		m.reload()
+	}
+}
+
+// reload rebuilds the plan after a mapping or ignore pattern was added
+func (m *Model) reload() {
+	plan, err := m.backend.Plan()
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	m.setPlan(plan)
+}
+
+// setPlan shows a new plan, keeping the selection and the changes made to
+// files that are still planned
+func (m *Model) setPlan(plan *organizer.Plan) {
+	current, hadSelection := m.selected()
+
+	m.plan = plan
+	m.files = append([]organizer.Operation(nil), plan.Operations...)
+	sort.SliceStable(m.files, func(i, j int) bool {
+		return lessPath(m.relative(m.files[i].Source), m.relative(m.files[j].Source))
+	})
+
+	planned := make(map[string]bool, len(m.files))
+	m.cursor = 0
+	for i, op := range m.files {
+		planned[op.Source] = true
+		if hadSelection && op.Source == current.Source {
+			m.cursor = i
+		}
+	}
+	for source := range m.overrides {
+		if !planned[source] {
+			delete(m.overrides, source)
+		}
+	}
+	for source := range m.skipped {
+		if !planned[source] {
+			delete(m.skipped, source)
+		}
+	}
+}
+
+// move moves the selection by delta files, stopping at either end
+func (m *Model) move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.files) {
+		m.cursor = len(m.files) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// selected returns the operation of the selected file, as planned
+func (m *Model) selected() (organizer.Operation, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.files) {
+		return organizer.Operation{}, false
+	}
+	return m.files[m.cursor], true
+}
+
+// effective returns an operation with the user's category applied
+func (m *Model) effective(op organizer.Operation) organizer.Operation {
+	if category, ok := m.overrides[op.Source]; ok {
+		return op.WithCategory(category)
+	}
+	return op
+}
+
+// inPlace reports whether a file will stay where it is, either because the
+// user skipped it or because its category is the folder it is already in
+func (m *Model) inPlace(op organizer.Operation) bool {
+	return m.skipped[op.Source] || m.effective(op).Destination == op.Source
+}
+
+// relative shortens a path for display by making it relative to the plan's root
+func (m *Model) relative(path string) string {
+	if rel, err := filepath.Rel(m.plan.RootPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// suggestIgnorePattern proposes a pattern for the file's extension, or its
+// name if it has none
+func suggestIgnorePattern(path string) string {
+	name := filepath.Base(path)
+	if ext := filepath.Ext(name); ext != "" && ext != name {
+		return "*" + ext
+	}
+	return name
+}
+
+// lessPath orders paths as a tree: the files of a directory come before its
+// subdirectories, and both are sorted by name
+func lessPath(a, b string) bool {
+	dirA, dirB := splitDir(filepath.Dir(a)), splitDir(filepath.Dir(b))
+	for i := 0; i < len(dirA) && i < len(dirB); i++ {
+		if dirA[i] != dirB[i] {
+			return dirA[i] < dirB[i]
+		}
+	}
+	if len(dirA) != len(dirB) {
+		return len(dirA) < len(dirB)
+	}
+	return filepath.Base(a) < filepath.Base(b)
+}
+
+// splitDir splits a relative directory into its names; "." has none
+func splitDir(dir string) []string {
+	if dir == "." || dir == "" {
+		return nil
+	}
+	return strings.Split(dir, string(filepath.Separator))
+}
@@ -0,0 +1,297 @@
+package tui
+
+import (
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRoot = "/home/user/Downloads"
+
+// fakeBackend plans the files of a fixed list with its own mappings and ignore patterns
+type fakeBackend struct {
+	files    []string
+	mappings map[string]string
+	ignored  []string
+	fail     error
+}
+
+func (b *fakeBackend) Plan() (*organizer.Plan, error) {
+	plan := &organizer.Plan{RootPath: testRoot}
+	for _, file := range b.files {
+		ext := filepath.Ext(file)
+		category, ok := b.mappings[ext]
+		if !ok || b.isIgnored(ext) {
+			continue
+		}
+		source := filepath.Join(testRoot, file)
+		plan.Operations = append(plan.Operations, organizer.Operation{
+			Source:      source,
+			Destination: filepath.Join(testRoot, category, filepath.Base(file)),
+			Category:    category,
+		})
+	}
+	return plan, nil
+}
+
+func (b *fakeBackend) isIgnored(ext string) bool {
+	for _, pattern := range b.ignored {
+		if pattern == "*"+ext {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *fakeBackend) AddMapping(ext, category string) (string, error) {
+	if b.fail != nil {
+		return "", b.fail
+	}
+	b.mappings[ext] = category
+	return "config.json", nil
+}
+
+func (b *fakeBackend) AddIgnorePattern(pattern string) (string, error) {
+	if b.fail != nil {
+		return "", b.fail
+	}
+	b.ignored = append(b.ignored, pattern)
+	return ".organizerignore", nil
+}
+
+func newTestModel(t *testing.T) (*Model, *fakeBackend) {
+	backend := &fakeBackend{
+		files:    []string{"report.pdf", "photos/cat.jpg", "photos/old/dog.jpg", "notes.txt", "song.mp3"},
+		mappings: map[string]string{".pdf": "Documents", ".txt": "Documents", ".jpg": "Images"},
+	}
+	plan, err := backend.Plan()
+	assert.NoError(t, err)
+	return NewModel(plan, backend), backend
+}
+
+// typeKeys sends the keys of a string, with \n as Enter
+func typeKeys(m *Model, text string) {
+	for _, r := range text {
+		if r == '\n' {
+			m.HandleKey(Key{Type: KeyEnter})
+		} else {
+			m.HandleKey(Key{Type: KeyRune, Rune: r})
+		}
+	}
+}
+
+// selectFile moves the selection to the file with the given name
+func selectFile(t *testing.T, m *Model, name string) {
+	for i, op := range m.files {
+		if filepath.Base(op.Source) == name {
+			m.cursor = i
+			return
+		}
+	}
+	t.Fatalf("%s is not planned", name)
+}
+
+func destinations(ops []organizer.Operation) map[string]string {
+	result := make(map[string]string)
+	for _, op := range ops {
+		rel, _ := filepath.Rel(testRoot, op.Destination)
+		result[filepath.Base(op.Source)] = rel
+	}
+	return result
+}
+
+func TestModelSourceTreeOrder(t *testing.T) {
+	m, _ := newTestModel(t)
+
+	// Files of a directory come before its subdirectories
+	var names []string
+	for _, op := range m.files {
+		names = append(names, m.relative(op.Source))
+	}
+	assert.Equal(t, []string{"notes.txt", "report.pdf", filepath.Join("photos", "cat.jpg"), filepath.Join("photos", "old", "dog.jpg")}, names)
+
+	typeKeys(m, "jj")
+	op, _ := m.selected()
+	assert.Equal(t, "cat.jpg", filepath.Base(op.Source))
+	m.HandleKey(Key{Type: KeyEnd})
+	m.HandleKey(Key{Type: KeyDown})
+	op, _ = m.selected()
+	assert.Equal(t, "dog.jpg", filepath.Base(op.Source))
+	m.HandleKey(Key{Type: KeyHome})
+	m.HandleKey(Key{Type: KeyUp})
+	assert.Equal(t, 0, m.cursor)
+}
+
+func TestModelChangeCategoryAndSkip(t *testing.T) {
+	m, _ := newTestModel(t)
+
+	selectFile(t, m, "report.pdf")
+	m.HandleKey(Key{Type: KeyRune, Rune: 'c'})
+	assert.Equal(t, "Documents", string(m.input))
+
+	// The suggestion is replaced by the new category
+	m.HandleKey(Key{Type: KeyCtrlU})
+	typeKeys(m, "Finanse")
+	m.HandleKey(Key{Type: KeyBackspace})
+	m.HandleKey(Key{Type: KeyBackspace})
+	typeKeys(m, "ce\n")
+	assert.Equal(t, promptNone, m.prompt)
+
+	selectFile(t, m, "notes.txt")
+	typeKeys(m, "x")
+
+	ops, apply := m.Result()
+	assert.False(t, apply)
+	assert.Equal(t, map[string]string{
+		"report.pdf": filepath.Join("Finance", "report.pdf"),
+		"cat.jpg":    filepath.Join("Images", "cat.jpg"),
+		"dog.jpg":    filepath.Join("Images", "dog.jpg"),
+	}, destinations(ops))
+
+	// Skipping again moves the file after all
+	typeKeys(m, "x")
+	ops, _ = m.Result()
+	assert.Len(t, ops, 4)
+
+	typeKeys(m, "a")
+	assert.True(t, m.Done())
+	_, apply = m.Result()
+	assert.True(t, apply)
+}
+
+func TestModelInvalidCategoryKeepsPrompt(t *testing.T) {
+	m, _ := newTestModel(t)
+
+	m.HandleKey(Key{Type: KeyRune, Rune: 'c'})
+	m.input = nil
+	typeKeys(m, "../Up\n")
+	assert.Equal(t, promptCategory, m.prompt)
+	assert.Contains(t, m.message, "Invalid category")
+
+	m.HandleKey(Key{Type: KeyEscape})
+	assert.Equal(t, promptNone, m.prompt)
+	assert.False(t, m.Done())
+	ops, _ := m.Result()
+	assert.Len(t, ops, 4)
+}
+
+func TestModelAddMapping(t *testing.T) {
+	m, backend := newTestModel(t)
+
+	selectFile(t, m, "cat.jpg")
+	m.HandleKey(Key{Type: KeyRune, Rune: 'm'})
+	assert.Equal(t, ".jpg=Images", string(m.input))
+
+	m.input = nil
+	typeKeys(m, "mp3\n")
+	assert.Equal(t, promptMapping, m.prompt)
+	assert.Contains(t, m.message, "expected '.ext=Category'")
+
+	m.input = nil
+	typeKeys(m, ".MP3 = Music\n")
+	assert.Equal(t, "Music", backend.mappings[".mp3"])
+	assert.Contains(t, m.message, "saved to config.json")
+
+	// The plan is rebuilt with the new file and the selection is kept
+	op, _ := m.selected()
+	assert.Equal(t, "cat.jpg", filepath.Base(op.Source))
+	ops, _ := m.Result()
+	assert.Equal(t, filepath.Join("Music", "song.mp3"), destinations(ops)["song.mp3"])
+}
+
+func TestModelAddIgnorePattern(t *testing.T) {
+	m, backend := newTestModel(t)
+
+	selectFile(t, m, "report.pdf")
+	typeKeys(m, "c")
+	m.input = nil
+	typeKeys(m, "Finance\n")
+	selectFile(t, m, "dog.jpg")
+	typeKeys(m, "i")
+	assert.Equal(t, "*.jpg", string(m.input))
+	typeKeys(m, "\n")
+	assert.Equal(t, []string{"*.jpg"}, backend.ignored)
+
+	// Changes to files that are still planned survive the rebuild
+	ops, _ := m.Result()
+	assert.Equal(t, map[string]string{
+		"report.pdf": filepath.Join("Finance", "report.pdf"),
+		"notes.txt":  filepath.Join("Documents", "notes.txt"),
+	}, destinations(ops))
+	assert.Equal(t, 0, m.cursor)
+}
+
+func TestModelBackendError(t *testing.T) {
+	m, backend := newTestModel(t)
+	backend.fail = fmt.Errorf("read-only file system")
+
+	typeKeys(m, "i\n")
+	assert.Equal(t, promptNone, m.prompt)
+	assert.Equal(t, "Error: read-only file system", m.message)
+	ops, _ := m.Result()
+	assert.Len(t, ops, 4)
+}
+
+func TestModelQuit(t *testing.T) {
+	for _, key := range []Key{{Type: KeyRune, Rune: 'q'}, {Type: KeyEscape}, {Type: KeyCtrlC}} {
+		m, _ := newTestModel(t)
+		m.HandleKey(key)
+		assert.True(t, m.Done())
+		_, apply := m.Result()
+		assert.False(t, apply)
+	}
+
+	// Ctrl+C quits from a prompt as well
+	m, _ := newTestModel(t)
+	typeKeys(m, "c")
+	m.HandleKey(Key{Type: KeyCtrlC})
+	assert.True(t, m.Done())
+}
+
+func TestModelRender(t *testing.T) {
+	m, _ := newTestModel(t)
+	selectFile(t, m, "cat.jpg")
+	typeKeys(m, "x")
+
+	screen := m.Render(80, 16)
+	lines := strings.Split(screen, "\n")
+	assert.Len(t, lines, 16)
+	for _, line := range lines {
+		assert.Equal(t, 80, len([]rune(line)))
+	}
+
+	assert.Contains(t, lines[0], "3 move(s), 1 left in place")
+	assert.Contains(t, screen, "> "+"  cat.jpg  (left in place)")
+	assert.Contains(t, screen, "  photos/")
+	assert.Contains(t, screen, "  Images/ (1)")
+	assert.Contains(t, screen, "  Left in place (1)")
+	assert.Contains(t, lines[15], helpLine)
+
+	typeKeys(m, "m")
+	lines = strings.Split(m.Render(80, 16), "\n")
+	assert.Contains(t, lines[15], "Mapping (.ext=Category): .jpg=Images_")
+
+	assert.Contains(t, m.Render(20, 5), "Terminal too small")
+}
+
+func TestRenderScrollsToSelection(t *testing.T) {
+	backend := &fakeBackend{mappings: map[string]string{".txt": "Documents"}}
+	for i := 0; i < 50; i++ {
+		backend.files = append(backend.files, fmt.Sprintf("file%02d.txt", i))
+	}
+	plan, err := backend.Plan()
+	assert.NoError(t, err)
+	m := NewModel(plan, backend)
+
+	m.HandleKey(Key{Type: KeyEnd})
+	screen := m.Render(80, 12)
+	assert.Contains(t, screen, "> file49.txt")
+	assert.NotContains(t, screen, "file00.txt")
+
+	m.HandleKey(Key{Type: KeyPageUp})
+	assert.Equal(t, 41, m.cursor)
+}
@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Minimum terminal size the panes are drawn in
+const (
+	minWidth  = 40
+	minHeight = 8
+)
+
+// Render draws the interface as height lines of width columns: the source
+// tree on the left, the resulting category tree on the right, and a status
+// and help line at the bottom
+func (m *Model) Render(width, height int) string {
+	if width < minWidth || height < minHeight {
+		return fit("Terminal too small, please enlarge it (q to quit)", width)
+	}
+
+	moves, inPlace := 0, 0
+	for _, op := range m.files {
+		if m.inPlace(op) {
+			inPlace++
+		} else {
+			moves++
+		}
+	}
+
+	leftWidth := (width - 1) / 2
+	rightWidth := width - 1 - leftWidth
+	m.page = height - 4
+
+	lines := make([]string, 0, height)
+	lines = append(lines, fit(fmt.Sprintf(" %s  %d move(s), %d left in place", m.plan.RootPath, moves, inPlace), width))
+	lines = append(lines, fit(" Source", leftWidth)+"│"+fit(" Categories", rightWidth))
+
+	left := window(m.sourceLines(), m.page)
+	right := window(m.categoryLines(), m.page)
+	for i := 0; i < m.page; i++ {
+		lines = append(lines, fit(left[i], leftWidth)+"│"+fit(right[i], rightWidth))
+	}
+
+	lines = append(lines, fit(" "+m.message, width))
+	switch m.prompt {
+	case promptCategory:
+		op, _ := m.selected()
+		lines = append(lines, fit(fmt.Sprintf(" Category for %s: %s_", filepath.Base(op.Source), string(m.input)), width))
+	case promptIgnore:
+		lines = append(lines, fit(" Ignore pattern: "+string(m.input)+"_", width))
+	case promptMapping:
+		lines = append(lines, fit(" Mapping (.ext=Category): "+string(m.input)+"_", width))
+	default:
+		lines = append(lines, fit(" "+helpLine, width))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paneLines are the lines of one pane and the index of the selected line
+type paneLines struct {
+	lines    []string
+	selected int
+}
+
+// sourceLines lists the planned files as a tree of the directories they are
+// in, with what will happen to each
+func (m *Model) sourceLines() paneLines {
+	pane := paneLines{selected: -1}
+	if len(m.files) == 0 {
+		pane.lines = []string{"  Nothing to organize"}
+		return pane
+	}
+
+	var previous []string
+	for i, op := range m.files {
+		rel := m.relative(op.Source)
+		dirs := splitDir(filepath.Dir(rel))
+
+		// Print the directories that differ from the previous file's
+		common := 0
+		for common < len(previous) && common < len(dirs) && previous[common] == dirs[common] {
+			common++
+		}
+		for depth := common; depth < len(dirs); depth++ {
+			pane.lines = append(pane.lines, "  "+strings.Repeat("  ", depth)+dirs[depth]+"/")
+		}
+		previous = dirs
+
+		status := "-> " + m.effective(op).Category
+		if m.inPlace(op) {
+			status = "(left in place)"
+		}
+		if i == m.cursor {
+			pane.selected = len(pane.lines)
+		}
+		pane.lines = append(pane.lines, marker(i == m.cursor)+strings.Repeat("  ", len(dirs))+filepath.Base(rel)+"  "+status)
+	}
+	return pane
+}
+
+// categoryLines lists the category folders with the files that will be moved
+// into them, followed by the files left in place
+func (m *Model) categoryLines() paneLines {
+	pane := paneLines{selected: -1}
+	groups := make(map[string][]int)
+	var stay []int
+	for i, op := range m.files {
+		if m.inPlace(op) {
+			stay = append(stay, i)
+			continue
+		}
+		category := m.effective(op).Category
+		groups[category] = append(groups[category], i)
+	}
+
+	categories := make([]string, 0, len(groups))
+	for category := range groups {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	addGroup := func(title string, files []int, name func(int) string) {
+		pane.lines = append(pane.lines, fmt.Sprintf("  %s (%d)", title, len(files)))
+		for _, i := range files {
+			if i == m.cursor {
+				pane.selected = len(pane.lines)
+			}
+			pane.lines = append(pane.lines, marker(i == m.cursor)+"  "+name(i))
+		}
+	}
+	for _, category := range categories {
+		addGroup(category+"/", groups[category], func(i int) string {
+			return filepath.Base(m.effective(m.files[i]).Destination)
+		})
+	}
+	if len(stay) > 0 {
+		addGroup("Left in place", stay, func(i int) string {
+			return m.relative(m.files[i].Source)
+		})
+	}
+	if len(pane.lines) == 0 {
+		pane.lines = []string{"  Nothing to move"}
+	}
+	return pane
+}
+
+// marker prefixes the selected file's lines
+func marker(selected bool) string {
+	if selected {
+		return "> "
+	}
+	return "  "
+}
+
+// window returns height lines of a pane, scrolled so that its selected line
+// is visible
+func window(pane paneLines, height int) []string {
+	offset := 0
+	if pane.selected >= height {
+		offset = pane.selected - height/2
+	}
+	if offset > len(pane.lines)-height {
+		offset = len(pane.lines) - height
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	visible := make([]string, height)
+	copy(visible, pane.lines[offset:])
+	return visible
+}
+
+// fit pads or truncates a line to exactly width characters
+func fit(line string, width int) string {
+	runes := []rune(line)
+	if len(runes) > width {
+		if width < 1 {
+			return ""
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return line + strings.Repeat(" ", width-len(runes))
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Escape sequences understood by xterm-compatible terminals
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// IsTerminal reports whether f is an interactive terminal the UI can run on
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Run shows the model full-screen on the terminal of in until the user
+// applies the plan or quits. The terminal is put in raw mode and restored
+// before Run returns.
+func Run(m *Model, in *os.File, out io.Writer) error {
+	fd := int(in.Fd())
+	if !IsTerminal(in) {
+		return fmt.Errorf("the terminal UI needs an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set up the terminal: %v", err)
+	}
+	defer term.Restore(fd, state)
+
+	fmt.Fprint(out, enterAltScreen)
+	defer fmt.Fprint(out, leaveAltScreen)
+
+	buf := make([]byte, 256)
+	for !m.Done() {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		// Raw mode turns off the translation of \n, so lines end with \r\n
+		fmt.Fprint(out, clearScreen+strings.ReplaceAll(m.Render(width, height), "\n", "\r\n"))
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return fmt.Errorf("failed to read from the terminal: %v", err)
+		}
+		for _, key := range ParseKeys(buf[:n]) {
+			m.HandleKey(key)
+			if m.Done() {
+				break
+			}
+		}
+	}
+	return nil
+}
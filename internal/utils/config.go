@@ -4,20 +4,145 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
 )
 
 // Config represents the configuration file structure
 type Config struct {
-	CustomMappings map[string]string `json:"customMappings"`
-	Description    string            `json:"description,omitempty"`
+	CustomMappings map[string]string  `json:"customMappings" yaml:"customMappings" toml:"customMappings"`
+	Description    string             `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+	Profiles       map[string]Profile `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+
+	// Run settings; environment variables and CLI flags take precedence over these
+	Path       string `json:"path,omitempty" yaml:"path,omitempty" toml:"path,omitempty"`
+	DryRun     *bool  `json:"dryRun,omitempty" yaml:"dryRun,omitempty" toml:"dryRun,omitempty"`
+	Progress   *bool  `json:"progress,omitempty" yaml:"progress,omitempty" toml:"progress,omitempty"`
+	Watch      *bool  `json:"watch,omitempty" yaml:"watch,omitempty" toml:"watch,omitempty"`
+	Profile    string `json:"profile,omitempty" yaml:"profile,omitempty" toml:"profile,omitempty"`
+	IgnoreFile string `json:"ignoreFile,omitempty" yaml:"ignoreFile,omitempty" toml:"ignoreFile,omitempty"`
+	LogFile    string `json:"logFile,omitempty" yaml:"logFile,omitempty" toml:"logFile,omitempty"`
+	LogLevel   string `json:"logLevel,omitempty" yaml:"logLevel,omitempty" toml:"logLevel,omitempty"`
+	LogFormat  string `json:"logFormat,omitempty" yaml:"logFormat,omitempty" toml:"logFormat,omitempty"`
+	NoLog      *bool  `json:"noLog,omitempty" yaml:"noLog,omitempty" toml:"noLog,omitempty"`
+	Output     string `json:"output,omitempty" yaml:"output,omitempty" toml:"output,omitempty"`
+
+	// Log rotation: size in MiB, number of rotated files and their age in days
+	LogMaxSize    *int  `json:"logMaxSize,omitempty" yaml:"logMaxSize,omitempty" toml:"logMaxSize,omitempty"`
+	LogMaxBackups *int  `json:"logMaxBackups,omitempty" yaml:"logMaxBackups,omitempty" toml:"logMaxBackups,omitempty"`
+	LogMaxAge     *int  `json:"logMaxAge,omitempty" yaml:"logMaxAge,omitempty" toml:"logMaxAge,omitempty"`
+	LogCompress   *bool `json:"logCompress,omitempty" yaml:"logCompress,omitempty" toml:"logCompress,omitempty"`
+
+	// Handling of files without a known category
+	UnknownPolicy  string   `json:"unknownPolicy,omitempty" yaml:"unknownPolicy,omitempty" toml:"unknownPolicy,omitempty"`
+	UnknownFolder  string   `json:"unknownFolder,omitempty" yaml:"unknownFolder,omitempty" toml:"unknownFolder,omitempty"`
+	SniffContent   *bool    `json:"sniffContent,omitempty" yaml:"sniffContent,omitempty" toml:"sniffContent,omitempty"`
+	SkipCategories []string `json:"skipCategories,omitempty" yaml:"skipCategories,omitempty" toml:"skipCategories,omitempty"`
+
+	file string // path the config was read from, if any
+}
+
+// File returns the path the config was read from, or an empty string
+func (c *Config) File() string {
+	return c.file
+}
+
+// ConfigFormat identifies the serialization format of a configuration file
+type ConfigFormat string
+
+const (
+	// ConfigFormatJSON is the original JSON configuration format
+	ConfigFormatJSON ConfigFormat = "JSON"
+	// ConfigFormatYAML allows comments and is detected by .yaml or .yml
+	ConfigFormatYAML ConfigFormat = "YAML"
+	// ConfigFormatTOML allows comments and is detected by .toml
+	ConfigFormatTOML ConfigFormat = "TOML"
+)
+
+// DetectConfigFormat determines the configuration format from the file extension.
+// Files with an unrecognized extension are treated as JSON for backwards compatibility.
+func DetectConfigFormat(configPath string) ConfigFormat {
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".yaml", ".yml":
+		return ConfigFormatYAML
+	case ".toml":
+		return ConfigFormatTOML
+	default:
+		return ConfigFormatJSON
+	}
+}
+
+// ParseConfig decodes configuration data in the given format
+func ParseConfig(data []byte, format ConfigFormat) (*Config, error) {
+	var config Config
+	var err error
+
+	switch format {
+	case ConfigFormatYAML:
+		err = yaml.Unmarshal(data, &config)
+	case ConfigFormatTOML:
+		err = toml.Unmarshal(data, &config)
+	default:
+		err = json.Unmarshal(data, &config)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", format, err)
+	}
+
+	return &config, nil
+}
+
+// ReadConfigFile reads and parses a configuration file, detecting its format by extension
+func ReadConfigFile(configPath string) (*Config, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	config, err := ParseConfig(data, DetectConfigFormat(configPath))
+	if err != nil {
+		return nil, err
+	}
+
+	config.file = configPath
+	return config, nil
+}
+
+// FindConfigFile returns the first existing file among the given candidates.
+// It returns an empty string if none of them exist.
+func FindConfigFile(candidates ...string) string {
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	return ""
 }
 
 // ExtensionMapping holds all extension mappings from various sources
 type ExtensionMapping struct {
 	mappings map[string]string
 	sources  map[string]string // tracks where each mapping came from
+	origins  map[string]string // the config file, variable or flag that set each mapping
+	rules    []Rule            // filename rules checked before extensions
+	output   io.Writer         // destination for informational messages
+}
+
+// MappingEntry describes one effective mapping and where it came from
+type MappingEntry struct {
+	Extension string `json:"extension"`
+	Category  string `json:"category"`
+	// Source is one of "default", "config", "env" or "cli"
+	Source string `json:"source"`
+	// Origin names the config file, environment variable or flag that set the mapping
+	Origin string `json:"origin,omitempty"`
 }
 
 // NewExtensionMapping creates a new extension mapping with default values
@@ -25,18 +150,22 @@ func NewExtensionMapping(defaultMappings map[string]string) *ExtensionMapping {
 	mapping := &ExtensionMapping{
 		mappings: make(map[string]string),
 		sources:  make(map[string]string),
+		origins:  make(map[string]string),
+		output:   os.Stdout,
 	}
 
 	// Add default mappings
 	for ext, category := range defaultMappings {
 		mapping.mappings[ext] = category
 		mapping.sources[ext] = "default"
+		mapping.origins[ext] = "built-in"
 	}
 
 	return mapping
 }
 
-// LoadConfig loads configuration from a JSON file and merges with existing mappings
+// LoadConfig loads configuration from a JSON, YAML or TOML file and merges with existing mappings.
+// The format is detected from the file extension (.json, .yaml/.yml, .toml).
 func (em *ExtensionMapping) LoadConfig(configPath string) error {
 	// Check if config file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -44,38 +173,38 @@ func (em *ExtensionMapping) LoadConfig(configPath string) error {
 		return nil
 	}
 
-	// Read config file
-	data, err := os.ReadFile(configPath)
+	config, err := ReadConfigFile(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to read config file: %v", err)
+		return err
 	}
 
-	// Parse JSON
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return fmt.Errorf("failed to parse config JSON: %v", err)
-	}
+	em.ApplyConfig(config)
+	return nil
+}
 
+// ApplyConfig merges the top-level custom mappings of a parsed config.
+// Invalid entries are reported as warnings and skipped.
+func (em *ExtensionMapping) ApplyConfig(config *Config) {
 	// Validate and merge custom mappings
 	count := 0
 	for ext, category := range config.CustomMappings {
 		if err := em.validateExtension(ext); err != nil {
-			fmt.Printf("Warning: Invalid extension '%s' in config: %v\n", ext, err)
+			fmt.Fprintf(em.output, "Warning: Invalid extension '%s' in config: %v\n", ext, err)
 			continue
 		}
 
 		if err := em.validateCategory(category); err != nil {
-			fmt.Printf("Warning: Invalid category '%s' for extension '%s': %v\n", category, ext, err)
+			fmt.Fprintf(em.output, "Warning: Invalid category '%s' for extension '%s': %v\n", category, ext, err)
 			continue
 		}
 
 		em.mappings[strings.ToLower(ext)] = category
 		em.sources[strings.ToLower(ext)] = "config"
+		em.origins[strings.ToLower(ext)] = config.file
 		count++
 	}
 
-	fmt.Printf("Loaded %d custom mappings from config file\n", count)
-	return nil
+	fmt.Fprintf(em.output, "Loaded %d custom mappings from config file\n", count)
 }
 
 // ApplyCLIMappings applies command-line mapping overrides
@@ -100,21 +229,61 @@ func (em *ExtensionMapping) ApplyCLIMappings(cliMappings []string) error {
 
 		em.mappings[strings.ToLower(ext)] = category
 		em.sources[strings.ToLower(ext)] = "cli"
+		em.origins[strings.ToLower(ext)] = "--map " + mapping
 		count++
 	}
 
 	if count > 0 {
-		fmt.Printf("Applied %d CLI mapping overrides\n", count)
+		fmt.Fprintf(em.output, "Applied %d CLI mapping overrides\n", count)
 	}
 	return nil
 }
 
+// SetMapping adds or replaces one mapping, recording the source ("config",
+// "env" or "cli") and the file, variable or flag it came from
+func (em *ExtensionMapping) SetMapping(ext, category, source, origin string) error {
+	if err := em.validateExtension(ext); err != nil {
+		return fmt.Errorf("invalid extension '%s': %v", ext, err)
+	}
+	if err := em.validateCategory(category); err != nil {
+		return fmt.Errorf("invalid category '%s': %v", category, err)
+	}
+
+	em.mappings[strings.ToLower(ext)] = category
+	em.sources[strings.ToLower(ext)] = source
+	em.origins[strings.ToLower(ext)] = origin
+	return nil
+}
+
 // GetMapping returns the category for a given extension
 func (em *ExtensionMapping) GetMapping(ext string) (string, bool) {
 	category, exists := em.mappings[strings.ToLower(ext)]
 	return category, exists
 }
 
+// GetEntries returns every effective mapping with its source, sorted by extension
+func (em *ExtensionMapping) GetEntries() []MappingEntry {
+	entries := make([]MappingEntry, 0, len(em.mappings))
+	for ext, category := range em.mappings {
+		entries = append(entries, MappingEntry{
+			Extension: ext,
+			Category:  category,
+			Source:    em.sources[ext],
+			Origin:    em.origins[ext],
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Extension < entries[j].Extension
+	})
+	return entries
+}
+
+// SetOutput redirects informational messages, e.g. to keep stdout machine-readable
+func (em *ExtensionMapping) SetOutput(w io.Writer) {
+	em.output = w
+}
+
 // GetMappings returns all current mappings
 func (em *ExtensionMapping) GetMappings() map[string]string {
 	result := make(map[string]string)
@@ -127,24 +296,30 @@ func (em *ExtensionMapping) GetMappings() map[string]string {
 // PrintSummary prints a summary of applied custom rules
 func (em *ExtensionMapping) PrintSummary() {
 	configCount := 0
+	envCount := 0
 	cliCount := 0
 
 	for _, source := range em.sources {
 		switch source {
 		case "config":
 			configCount++
+		case "env":
+			envCount++
 		case "cli":
 			cliCount++
 		}
 	}
 
-	if configCount > 0 || cliCount > 0 {
-		fmt.Printf("\n📋 Custom Rules Applied:\n")
+	if configCount > 0 || envCount > 0 || cliCount > 0 {
+		fmt.Fprintf(em.output, "\n📋 Custom Rules Applied:\n")
 		if configCount > 0 {
-			fmt.Printf("  📄 Config file mappings: %d\n", configCount)
+			fmt.Fprintf(em.output, "  📄 Config file mappings: %d\n", configCount)
+		}
+		if envCount > 0 {
+			fmt.Fprintf(em.output, "  🌱 Environment overrides: %d\n", envCount)
 		}
 		if cliCount > 0 {
-			fmt.Printf("  ⚡ CLI overrides: %d\n", cliCount)
+			fmt.Fprintf(em.output, "  ⚡ CLI overrides: %d\n", cliCount)
 		}
 	}
 }
@@ -168,6 +343,11 @@ func (em *ExtensionMapping) validateExtension(ext string) error {
 
 // validateCategory validates that a category name is reasonable
 func (em *ExtensionMapping) validateCategory(category string) error {
+	return ValidateCategory(category)
+}
+
+// ValidateCategory validates that a category name is usable as a folder name
+func ValidateCategory(category string) error {
 	if category == "" {
 		return fmt.Errorf("category cannot be empty")
 	}
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetConfigMapping adds or replaces one custom mapping in a config file,
+// creating the file if it does not exist. YAML and TOML files keep their
+// comments and layout; JSON files are rewritten with sorted keys.
+func SetConfigMapping(configPath, ext, category string) error {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if !strings.HasPrefix(ext, ".") || len(ext) == 1 {
+		return fmt.Errorf("invalid extension '%s': extension must start with '.' followed by a name", ext)
+	}
+	if err := ValidateCategory(category); err != nil {
+		return fmt.Errorf("invalid category '%s': %v", category, err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	format := DetectConfigFormat(configPath)
+	var updated []byte
+	switch format {
+	case ConfigFormatYAML:
+		updated, err = setYAMLMapping(data, ext, category)
+	case ConfigFormatTOML:
+		updated, err = setTOMLMapping(data, ext, category)
+	default:
+		updated, err = setJSONMapping(data, ext, category)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update config %s: %v", configPath, err)
+	}
+
+	// Make sure the edit produced a config that reads back as intended
+	config, err := ParseConfig(updated, format)
+	if err != nil {
+		return fmt.Errorf("failed to update config %s: %v", configPath, err)
+	}
+	if config.CustomMappings[ext] != category {
+		return fmt.Errorf("failed to update config %s: mapping for %s not found after the edit", configPath, ext)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(configPath, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+	return nil
+}
+
+// setJSONMapping sets the mapping in a JSON document, keeping its other fields
+func setJSONMapping(data []byte, ext, category string) ([]byte, error) {
+	document := make(map[string]interface{})
+	if len(bytes.TrimSpace(data)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, err
+		}
+	}
+
+	mappings, ok := document["customMappings"].(map[string]interface{})
+	if !ok {
+		if existing, found := document["customMappings"]; found && existing != nil {
+			return nil, fmt.Errorf("customMappings is not an object")
+		}
+		mappings = make(map[string]interface{})
+	}
+	for key := range mappings {
+		if strings.EqualFold(key, ext) {
+			delete(mappings, key)
+		}
+	}
+	mappings[ext] = category
+	document["customMappings"] = mappings
+
+	updated, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(updated, '\n'), nil
+}
+
+// setYAMLMapping sets the mapping in the node tree of a YAML document so that
+// comments survive the edit
+func setYAMLMapping(data []byte, ext, category string) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the document is not a mapping")
+	}
+
+	scalar := func(value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+
+	var mappings *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "customMappings" {
+			mappings = root.Content[i+1]
+			break
+		}
+	}
+	if mappings == nil {
+		mappings = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, scalar("customMappings"), mappings)
+	}
+	if mappings.Kind == yaml.ScalarNode && mappings.Tag == "!!null" {
+		*mappings = yaml.Node{Kind: yaml.MappingNode}
+	}
+	if mappings.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("customMappings is not a mapping")
+	}
+
+	// Replace the first entry for the extension in any case, keeping its
+	// comments, and drop the other case variants
+	found := false
+	content := mappings.Content[:0]
+	for i := 0; i+1 < len(mappings.Content); i += 2 {
+		key := mappings.Content[i]
+		if strings.EqualFold(key.Value, ext) {
+			if found {
+				continue
+			}
+			key.Value, key.Tag, key.Style = ext, "!!str", 0
+			content = append(content, key, scalar(category))
+			found = true
+			continue
+		}
+		content = append(content, key, mappings.Content[i+1])
+	}
+	mappings.Content = content
+	if !found {
+		mappings.Content = append(mappings.Content, scalar(ext), scalar(category))
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// setTOMLMapping sets the mapping by editing the lines of the [customMappings]
+// table, which keeps the rest of the file untouched
+func setTOMLMapping(data []byte, ext, category string) ([]byte, error) {
+	entry := fmt.Sprintf("%q = %q", ext, category)
+	text := strings.TrimRight(string(data), "\n")
+	var lines []string
+	if text != "" {
+		lines = strings.Split(text, "\n")
+	}
+
+	// Find the table and the last key in it
+	start, last := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if start < 0 {
+			if trimmed == "[customMappings]" {
+				start, last = i, i
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "[") {
+			break
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, _, _ := strings.Cut(trimmed, "=")
+		if strings.EqualFold(strings.Trim(strings.TrimSpace(key), `"'`), ext) {
+			lines[i] = entry
+			return joinLines(lines), nil
+		}
+		last = i
+	}
+
+	if start < 0 {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "[customMappings]", entry)
+		return joinLines(lines), nil
+	}
+
+	lines = append(lines[:last+1], append([]string{entry}, lines[last+1:]...)...)
+	return joinLines(lines), nil
+}
+
+// joinLines joins lines into file content ending with a newline
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetConfigMappingJSON(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config", "config.json")
+
+	// A missing file is created
+	assert.NoError(t, SetConfigMapping(configPath, ".PSD", "Design"))
+	config, err := ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{".psd": "Design"}, config.CustomMappings)
+
+	// Other fields survive and an existing mapping is replaced
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{"description": "mine", "logMaxSize": 5, "customMappings": {".PSD": "Images", ".md": "Notes"}}`), 0644))
+	assert.NoError(t, SetConfigMapping(configPath, ".psd", "Design"))
+	config, err = ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", config.Description)
+	assert.Equal(t, 5, *config.LogMaxSize)
+	assert.Equal(t, map[string]string{".psd": "Design", ".md": "Notes"}, config.CustomMappings)
+}
+
+func TestSetConfigMappingYAML(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `# My settings
+description: mine
+customMappings:
+  .psd: Images # wrong
+  .md: Notes
+`
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	assert.NoError(t, SetConfigMapping(configPath, ".psd", "Design"))
+	assert.NoError(t, SetConfigMapping(configPath, ".sketch", "Design"))
+
+	data, err := os.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "# My settings")
+	config, err := ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{".psd": "Design", ".md": "Notes", ".sketch": "Design"}, config.CustomMappings)
+
+	// A document without mappings gets them added
+	assert.NoError(t, os.WriteFile(configPath, []byte("description: mine\n"), 0644))
+	assert.NoError(t, SetConfigMapping(configPath, ".psd", "Design"))
+	config, err = ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{".psd": "Design"}, config.CustomMappings)
+}
+
+func TestSetConfigMappingYAMLUpperCaseKey(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	content := `customMappings:
+  # Scanned documents
+  .PDF: Docs
+  .md: Notes
+  .Pdf: Scans
+`
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	assert.NoError(t, SetConfigMapping(configPath, ".pdf", "Documents"))
+
+	data, err := os.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "# Scanned documents\n  .pdf: Documents\n")
+	config, err := ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{".pdf": "Documents", ".md": "Notes"}, config.CustomMappings)
+}
+
+func TestSetConfigMappingTOML(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	content := `# My settings
+description = "mine"
+
+[customMappings]
+".psd" = "Images" # wrong
+".md" = "Notes"
+
+[profiles.work]
+description = "work"
+`
+	assert.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	assert.NoError(t, SetConfigMapping(configPath, ".psd", "Design"))
+	assert.NoError(t, SetConfigMapping(configPath, ".sketch", "Design"))
+
+	data, err := os.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "# My settings")
+	config, err := ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{".psd": "Design", ".md": "Notes", ".sketch": "Design"}, config.CustomMappings)
+	assert.Contains(t, config.Profiles, "work")
+
+	// The table is appended when the file has none
+	assert.NoError(t, os.WriteFile(configPath, []byte("description = \"mine\"\n"), 0644))
+	assert.NoError(t, SetConfigMapping(configPath, ".psd", "Design"))
+	config, err = ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", config.Description)
+	assert.Equal(t, map[string]string{".psd": "Design"}, config.CustomMappings)
+}
+
+func TestSetConfigMappingInvalid(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	assert.Error(t, SetConfigMapping(configPath, "psd", "Design"))
+	assert.Error(t, SetConfigMapping(configPath, ".", "Design"))
+	assert.Error(t, SetConfigMapping(configPath, ".psd", "../Design"))
+	_, err := os.Stat(configPath)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -161,6 +161,19 @@ func TestApplyCLIMappingsInvalidFormat(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid extension")
 }
 
+func TestSetMapping(t *testing.T) {
+	mapping := NewExtensionMapping(map[string]string{".txt": "Documents"})
+
+	assert.NoError(t, mapping.SetMapping(".TXT", "Notes", "config", "config/config.json"))
+	category, exists := mapping.GetMapping(".txt")
+	assert.True(t, exists)
+	assert.Equal(t, "Notes", category)
+	assert.Contains(t, mapping.GetEntries(), MappingEntry{Extension: ".txt", Category: "Notes", Source: "config", Origin: "config/config.json"})
+
+	assert.Error(t, mapping.SetMapping("txt", "Notes", "config", ""))
+	assert.Error(t, mapping.SetMapping(".txt", "", "config", ""))
+}
+
 func TestValidateExtension(t *testing.T) {
 	mapping := NewExtensionMapping(map[string]string{})
 
@@ -267,3 +280,130 @@ func TestPriorityOrder(t *testing.T) {
 	assert.True(t, exists)
 	assert.Equal(t, "CLIDocuments", category)
 }
+
+func TestDetectConfigFormat(t *testing.T) {
+	assert.Equal(t, ConfigFormatJSON, DetectConfigFormat("config.json"))
+	assert.Equal(t, ConfigFormatYAML, DetectConfigFormat("config.yaml"))
+	assert.Equal(t, ConfigFormatYAML, DetectConfigFormat("config.YML"))
+	assert.Equal(t, ConfigFormatTOML, DetectConfigFormat("config.toml"))
+
+	// Unrecognized extensions fall back to JSON
+	assert.Equal(t, ConfigFormatJSON, DetectConfigFormat("config"))
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := `# Team mappings
+description: Test config
+customMappings:
+  # Markdown notes live with the docs
+  .md: Notes
+  .bak: Backups
+`
+
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	mapping := NewExtensionMapping(map[string]string{".txt": "Documents"})
+	err = mapping.LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	category, exists := mapping.GetMapping(".md")
+	assert.True(t, exists)
+	assert.Equal(t, "Notes", category)
+
+	category, exists = mapping.GetMapping(".bak")
+	assert.True(t, exists)
+	assert.Equal(t, "Backups", category)
+}
+
+func TestLoadConfigTOML(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.toml")
+	configContent := `# Team mappings
+description = "Test config"
+
+[customMappings]
+# Markdown notes live with the docs
+".md" = "Notes"
+".log" = "Logs"
+`
+
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	mapping := NewExtensionMapping(map[string]string{".txt": "Documents"})
+	err = mapping.LoadConfig(configPath)
+	assert.NoError(t, err)
+
+	category, exists := mapping.GetMapping(".md")
+	assert.True(t, exists)
+	assert.Equal(t, "Notes", category)
+
+	category, exists = mapping.GetMapping(".log")
+	assert.True(t, exists)
+	assert.Equal(t, "Logs", category)
+}
+
+func TestLoadConfigInvalidYAMLAndTOML(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	yamlPath := filepath.Join(tempDir, "config.yaml")
+	err = os.WriteFile(yamlPath, []byte("customMappings: [unterminated\n"), 0644)
+	assert.NoError(t, err)
+
+	tomlPath := filepath.Join(tempDir, "config.toml")
+	err = os.WriteFile(tomlPath, []byte("[customMappings\n"), 0644)
+	assert.NoError(t, err)
+
+	mapping := NewExtensionMapping(map[string]string{})
+
+	err = mapping.LoadConfig(yamlPath)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse config YAML")
+
+	err = mapping.LoadConfig(tomlPath)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse config TOML")
+}
+
+func TestGetEntriesProvenance(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := "customMappings:\n  .md: Notes\nprofiles:\n  work:\n    customMappings:\n      .log: Logs\n"
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	config, err := ReadConfigFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, configPath, config.File())
+
+	mapping := NewExtensionMapping(map[string]string{".txt": "Documents", ".bak": "Backups"})
+	mapping.ApplyConfig(config)
+
+	profile, err := config.ResolveProfile("work")
+	assert.NoError(t, err)
+	assert.NoError(t, mapping.ApplyProfile(profile))
+	assert.NoError(t, mapping.ApplyEnvMappings(".bak=Old"))
+	assert.NoError(t, mapping.ApplyCLIMappings([]string{".go=Golang"}))
+
+	assert.Equal(t, []MappingEntry{
+		{Extension: ".bak", Category: "Old", Source: "env", Origin: "GFO_MAP"},
+		{Extension: ".go", Category: "Golang", Source: "cli", Origin: "--map .go=Golang"},
+		{Extension: ".log", Category: "Logs", Source: "config", Origin: configPath + " (profile work)"},
+		{Extension: ".md", Category: "Notes", Source: "config", Origin: configPath},
+		{Extension: ".txt", Category: "Documents", Source: "default", Origin: "built-in"},
+	}, mapping.GetEntries())
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ReadFileList reads a list of file names, one per line or, with null set,
+// separated by NUL characters as written by find -print0. Empty names are
+// skipped, and so are the carriage returns of lines ending in \r\n.
+func ReadFileList(r io.Reader, null bool) ([]string, error) {
+	separator := byte('\n')
+	if null {
+		separator = 0
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if i := bytes.IndexByte(data, separator); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF && len(data) > 0 {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+
+	files := []string{}
+	for scanner.Scan() {
+		name := scanner.Text()
+		if !null {
+			name = strings.TrimSuffix(name, "\r")
+		}
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %v", err)
+	}
+	return files, nil
+}
+
+// ReadFileListFrom reads a list of file names with ReadFileList from a file,
+// or from stdin if path is "-"
+func ReadFileListFrom(path string, null bool) ([]string, error) {
+	if path == "-" {
+		return ReadFileList(os.Stdin, null)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file list: %v", err)
+	}
+	defer file.Close()
+	return ReadFileList(file, null)
+}
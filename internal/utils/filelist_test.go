@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFileList(t *testing.T) {
+	files, err := ReadFileList(strings.NewReader("a.pdf\r\n\nsub dir/b.jpg\nlast.txt"), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.pdf", "sub dir/b.jpg", "last.txt"}, files)
+
+	// NUL separated names may contain newlines
+	files, err = ReadFileList(strings.NewReader("line\nbreak.txt\x00\x00c.doc\x00"), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"line\nbreak.txt", "c.doc"}, files)
+
+	// An empty list is not nil, so it selects no files rather than all of them
+	files, err = ReadFileList(strings.NewReader(""), false)
+	assert.NoError(t, err)
+	assert.NotNil(t, files)
+	assert.Empty(t, files)
+}
+
+func TestReadFileListFrom(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "files.txt")
+	assert.NoError(t, os.WriteFile(listPath, []byte("a.pdf\nb.jpg\n"), 0644))
+
+	files, err := ReadFileListFrom(listPath, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.pdf", "b.jpg"}, files)
+
+	_, err = ReadFileListFrom(filepath.Join(t.TempDir(), "missing.txt"), false)
+	assert.ErrorContains(t, err, "failed to open file list")
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 type IgnoreManager struct {
 	patterns []string
 	rootPath string
+	output   io.Writer // destination for informational messages
 }
 
 // NewIgnoreManager creates a new ignore manager
@@ -19,6 +21,7 @@ func NewIgnoreManager(rootPath string) *IgnoreManager {
 	return &IgnoreManager{
 		patterns: make([]string, 0),
 		rootPath: rootPath,
+		output:   os.Stdout,
 	}
 }
 
@@ -58,7 +61,7 @@ func (im *IgnoreManager) LoadIgnoreFile(ignoreFilePath string) error {
 	}
 
 	if patternCount > 0 {
-		fmt.Printf("Loaded %d ignore patterns from .organizerignore\n", patternCount)
+		fmt.Fprintf(im.output, "Loaded %d ignore patterns from .organizerignore\n", patternCount)
 	}
 
 	return nil
@@ -66,6 +69,12 @@ func (im *IgnoreManager) LoadIgnoreFile(ignoreFilePath string) error {
 
 // ShouldIgnore checks if a file path should be ignored based on patterns
 func (im *IgnoreManager) ShouldIgnore(filePath string) bool {
+	_, ignored := im.MatchingPattern(filePath)
+	return ignored
+}
+
+// MatchingPattern returns the first pattern that ignores a file path, if any
+func (im *IgnoreManager) MatchingPattern(filePath string) (string, bool) {
 	// Convert to relative path from root for consistent matching
 	relPath, err := filepath.Rel(im.rootPath, filePath)
 	if err != nil {
@@ -78,11 +87,33 @@ func (im *IgnoreManager) ShouldIgnore(filePath string) bool {
 
 	for _, pattern := range im.patterns {
 		if im.matchPattern(pattern, relPath, fileName) {
-			return true
+			return pattern, true
 		}
 	}
 
-	return false
+	return "", false
+}
+
+// MatchingPatternOrParent returns the pattern that excludes a path or one of
+// its parent directories below the root, parents first, since a scan does not
+// enter ignored directories. Paths outside the root are matched as they are.
+func (im *IgnoreManager) MatchingPatternOrParent(filePath string) (string, bool) {
+	rel, err := filepath.Rel(im.rootPath, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return im.MatchingPattern(filePath)
+	}
+
+	current := im.rootPath
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if part == "." {
+			continue
+		}
+		current = filepath.Join(current, part)
+		if pattern, matched := im.MatchingPattern(current); matched {
+			return pattern, true
+		}
+	}
+	return "", false
 }
 
 // matchPattern checks if a file path matches an ignore pattern
@@ -175,6 +206,58 @@ func (im *IgnoreManager) GetPatterns() []string {
 // PrintSummary prints a summary of loaded ignore patterns
 func (im *IgnoreManager) PrintSummary() {
 	if len(im.patterns) > 0 {
-		fmt.Printf("  🚫 Ignore patterns: %d\n", len(im.patterns))
+		fmt.Fprintf(im.output, "  🚫 Ignore patterns: %d\n", len(im.patterns))
+	}
+}
+
+// SetOutput sets where informational messages are written (os.Stdout by default)
+func (im *IgnoreManager) SetOutput(w io.Writer) {
+	im.output = w
+}
+
+// AddPatterns adds ignore patterns in addition to those loaded from a file
+func (im *IgnoreManager) AddPatterns(patterns []string) {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" || strings.HasPrefix(pattern, "#") {
+			continue
+		}
+		im.patterns = append(im.patterns, pattern)
 	}
 }
+
+// AppendIgnorePattern adds a pattern to the end of an ignore file, creating
+// the file if needed. A pattern the file already contains is not added again.
+func AppendIgnorePattern(ignoreFilePath, pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return fmt.Errorf("invalid ignore pattern '%s'", pattern)
+	}
+
+	data, err := os.ReadFile(ignoreFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read ignore file: %v", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+
+	file, err := os.OpenFile(ignoreFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open ignore file: %v", err)
+	}
+	line := pattern + "\n"
+	if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+		line = "\n" + line
+	}
+	if _, err := file.WriteString(line); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write ignore file: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write ignore file: %v", err)
+	}
+	return nil
+}
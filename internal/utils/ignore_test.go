@@ -304,3 +304,60 @@ func TestGetPatterns(t *testing.T) {
 	patterns[0] = "modified"
 	assert.Equal(t, "*.tmp", manager.patterns[0])
 }
+
+func TestMatchingPattern(t *testing.T) {
+	manager := NewIgnoreManager("/test")
+	manager.AddPatterns([]string{"*.tmp", "build/", "notes.txt"})
+
+	pattern, ignored := manager.MatchingPattern("/test/build/output.bin")
+	assert.True(t, ignored)
+	assert.Equal(t, "build/", pattern)
+
+	pattern, ignored = manager.MatchingPattern("/test/docs/cache.tmp")
+	assert.True(t, ignored)
+	assert.Equal(t, "*.tmp", pattern)
+
+	pattern, ignored = manager.MatchingPattern("/test/docs/readme.md")
+	assert.False(t, ignored)
+	assert.Empty(t, pattern)
+}
+
+func TestMatchingPatternOrParent(t *testing.T) {
+	manager := NewIgnoreManager("/test")
+	manager.AddPatterns([]string{"/private", "*.tmp"})
+
+	// The pattern only matches the directory, which a scan would not enter
+	_, ignored := manager.MatchingPattern("/test/private/a.pdf")
+	assert.False(t, ignored)
+	pattern, ignored := manager.MatchingPatternOrParent("/test/private/a.pdf")
+	assert.True(t, ignored)
+	assert.Equal(t, "/private", pattern)
+
+	pattern, ignored = manager.MatchingPatternOrParent("/test/docs/cache.tmp")
+	assert.True(t, ignored)
+	assert.Equal(t, "*.tmp", pattern)
+
+	_, ignored = manager.MatchingPatternOrParent("/test/docs/private.pdf")
+	assert.False(t, ignored)
+}
+
+func TestAppendIgnorePattern(t *testing.T) {
+	ignoreFile := filepath.Join(t.TempDir(), ".organizerignore")
+
+	// The file is created on the first pattern
+	assert.NoError(t, AppendIgnorePattern(ignoreFile, "*.tmp"))
+	data, err := os.ReadFile(ignoreFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "*.tmp\n", string(data))
+
+	// Duplicates are skipped and a missing final newline is added
+	assert.NoError(t, os.WriteFile(ignoreFile, []byte("# comment\n*.tmp\nbuild/"), 0644))
+	assert.NoError(t, AppendIgnorePattern(ignoreFile, " *.tmp "))
+	assert.NoError(t, AppendIgnorePattern(ignoreFile, "*.bak"))
+	data, err = os.ReadFile(ignoreFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "# comment\n*.tmp\nbuild/\n*.bak\n", string(data))
+
+	assert.Error(t, AppendIgnorePattern(ignoreFile, ""))
+	assert.Error(t, AppendIgnorePattern(ignoreFile, "# not a pattern"))
+}
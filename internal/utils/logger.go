@@ -1,78 +1,266 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
 )
 
+// LogFormat selects how log records are written
+type LogFormat string
+
+const (
+	// LogFormatText writes key=value records (the default)
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes one JSON object per record
+	LogFormatJSON LogFormat = "json"
+)
+
+// ParseLogFormat validates a log format name. An empty name selects text.
+func ParseLogFormat(name string) (LogFormat, error) {
+	switch format := LogFormat(strings.ToLower(name)); format {
+	case "":
+		return LogFormatText, nil
+	case LogFormatText, LogFormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown log format '%s', expected one of: text, json", name)
+	}
+}
+
+// ParseLogLevel validates a log level name: debug, info, warn or error. An
+// empty name selects info.
+func ParseLogLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level '%s', expected one of: debug, info, warn, error", name)
+	}
+}
+
+// LoggerOptions configures the records a Logger writes
+type LoggerOptions struct {
+	// Level is the minimum level written (the zero value is info)
+	Level slog.Level
+	// Format is the record format (empty for text)
+	Format LogFormat
+	// Rotate decides when the log file is rotated and which rotated files are kept
+	Rotate RotateOptions
+}
+
+// LogFields are the per-operation fields attached to a log record. Empty
+// fields are left out.
+type LogFields struct {
+	Op       string
+	Src      string
+	Dst      string
+	Category string
+	Bytes    int64
+	Err      error
+}
+
+// attrs returns the non-empty fields as slog attributes
+func (f LogFields) attrs() []slog.Attr {
+	var attrs []slog.Attr
+	if f.Op != "" {
+		attrs = append(attrs, slog.String("op", f.Op))
+	}
+	if f.Src != "" {
+		attrs = append(attrs, slog.String("src", f.Src))
+	}
+	if f.Dst != "" {
+		attrs = append(attrs, slog.String("dst", f.Dst))
+	}
+	if f.Category != "" {
+		attrs = append(attrs, slog.String("category", f.Category))
+	}
+	if f.Bytes != 0 {
+		attrs = append(attrs, slog.Int64("bytes", f.Bytes))
+	}
+	if f.Err != nil {
+		attrs = append(attrs, slog.String("err", f.Err.Error()))
+	}
+	return attrs
+}
+
+// Logger writes the operation log to a sink. Every record carries the ID of
+// the session that wrote it. A nil *Logger discards everything, so callers
+// never need to check for one.
 type Logger struct {
-	file   *os.File
-	logger *log.Logger
+	sink    LogSink
+	session string
 }
 
-// NewLogger creates a new logger that writes to organizer.log
+// NewLogger creates a new logger that appends text records at info level to a file
 func NewLogger(logPath string) (*Logger, error) {
-	// Open log file in append mode, create if doesn't exist
-	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	return NewLoggerWithOptions(logPath, LoggerOptions{})
+}
+
+// NewLoggerWithOptions creates a new logger that appends to a file, creating
+// its directory if needed, and rotates it as configured
+func NewLoggerWithOptions(logPath string, opts LoggerOptions) (*Logger, error) {
+	sink, err := NewFileSink(logPath, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %v", err)
+		return nil, err
 	}
+	return NewSinkLogger(sink), nil
+}
+
+// NewWriterLogger creates a logger that writes to w, which is not closed by Close
+func NewWriterLogger(w io.Writer, opts LoggerOptions) *Logger {
+	return NewSinkLogger(NewWriterSink(w, opts))
+}
 
-	logger := log.New(file, "", log.LstdFlags)
+// NewSinkLogger creates a logger for a new session that writes to a sink
+func NewSinkLogger(sink LogSink) *Logger {
+	l := &Logger{sink: sink, session: newSessionID()}
 
 	// Write session separator
-	logger.Println("=== New Organizer Session Started ===")
+	l.Info("=== New Organizer Session Started ===", LogFields{})
+	return l
+}
 
-	return &Logger{
-		file:   file,
-		logger: logger,
-	}, nil
+// AsLogger returns a logger writing to a sink. A sink that is already a Logger
+// is returned as is; nil gives the nil Logger, which discards everything.
+// Unlike NewSinkLogger, no session header is written.
+func AsLogger(sink LogSink) *Logger {
+	switch typed := sink.(type) {
+	case nil:
+		return nil
+	case *Logger:
+		return typed
+	default:
+		return &Logger{sink: sink, session: newSessionID()}
+	}
+}
+
+// newSessionID returns a short random ID that groups the records of one run
+func newSessionID() string {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+// Session returns the ID attached to every record of this logger
+func (l *Logger) Session() string {
+	if l == nil {
+		return ""
+	}
+	return l.session
+}
+
+// Handle sends a record to the sink, stamped with the time and session if
+// missing. It makes a Logger usable as a LogSink.
+func (l *Logger) Handle(record LogRecord) {
+	if l == nil || l.sink == nil {
+		return
+	}
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	if record.Session == "" {
+		record.Session = l.session
+	}
+	l.sink.Handle(record)
+}
+
+// Log writes a record with per-operation fields
+func (l *Logger) Log(level slog.Level, msg string, fields LogFields) {
+	l.Handle(LogRecord{Level: level, Message: msg, Fields: fields})
+}
+
+// Debug writes a debug record with per-operation fields
+func (l *Logger) Debug(msg string, fields LogFields) {
+	l.Log(slog.LevelDebug, msg, fields)
+}
+
+// Info writes an info record with per-operation fields
+func (l *Logger) Info(msg string, fields LogFields) {
+	l.Log(slog.LevelInfo, msg, fields)
+}
+
+// Warn writes a warning record with per-operation fields
+func (l *Logger) Warn(msg string, fields LogFields) {
+	l.Log(slog.LevelWarn, msg, fields)
+}
+
+// Error writes an error record with per-operation fields
+func (l *Logger) Error(msg string, fields LogFields) {
+	l.Log(slog.LevelError, msg, fields)
 }
 
 // LogDryRun logs a dry-run action
 func (l *Logger) LogDryRun(source, destination string) {
-	l.logger.Printf("[DRY-RUN] Would move: %s -> %s", source, destination)
+	l.LogPlanned(LogFields{Src: source, Dst: destination})
+}
+
+// LogPlanned logs a move that a dry run would perform
+func (l *Logger) LogPlanned(fields LogFields) {
+	fields.Op = "dry-run"
+	l.Info(fmt.Sprintf("[DRY-RUN] Would move: %s -> %s", fields.Src, fields.Dst), fields)
 }
 
 // LogMove logs an actual file move
 func (l *Logger) LogMove(source, destination string) {
-	l.logger.Printf("[MOVE] Moved: %s -> %s", source, destination)
+	l.LogMoved(LogFields{Src: source, Dst: destination})
+}
+
+// LogMoved logs an actual file move
+func (l *Logger) LogMoved(fields LogFields) {
+	fields.Op = "move"
+	l.Info(fmt.Sprintf("[MOVE] Moved: %s -> %s", fields.Src, fields.Dst), fields)
+}
+
+// LogSkipped logs a file left in place
+func (l *Logger) LogSkipped(fields LogFields, reason string) {
+	fields.Op = "skip"
+	l.Info(fmt.Sprintf("[SKIP] Skipped %s: %s", fields.Src, reason), fields)
 }
 
 // LogFolderCreation logs folder creation
 func (l *Logger) LogFolderCreation(folderPath string, isDryRun bool) {
 	if isDryRun {
-		l.logger.Printf("[DRY-RUN] Would create folder: %s", folderPath)
+		l.Info(fmt.Sprintf("[DRY-RUN] Would create folder: %s", folderPath), LogFields{Op: "mkdir", Dst: folderPath})
 	} else {
-		l.logger.Printf("[FOLDER] Created folder: %s", folderPath)
+		l.Info(fmt.Sprintf("[FOLDER] Created folder: %s", folderPath), LogFields{Op: "mkdir", Dst: folderPath})
 	}
 }
 
 // LogError logs an error
 func (l *Logger) LogError(operation, filePath string, err error) {
-	l.logger.Printf("[ERROR] %s failed for %s: %v", operation, filePath, err)
+	l.Error(fmt.Sprintf("[ERROR] %s failed for %s: %v", operation, filePath, err),
+		LogFields{Op: operation, Src: filePath, Err: err})
 }
 
 // LogSummary logs the final summary statistics
 func (l *Logger) LogSummary(stats Summary) {
-	l.logger.Printf("[SUMMARY] Files scanned: %d, moved: %d, folders created: %d, skipped: %d",
-		stats.FilesScanned, stats.FilesMoved, stats.FoldersCreated, stats.FilesSkipped)
+	l.Handle(LogRecord{
+		Level: slog.LevelInfo,
+		Message: fmt.Sprintf("[SUMMARY] Files scanned: %d, moved: %d, folders created: %d, skipped: %d, ignored: %d, failed: %d, duration: %s",
+			stats.FilesScanned, stats.FilesMoved, stats.FoldersCreated, stats.FilesSkipped, stats.SkippedByIgnore, len(stats.Failures), stats.Duration.Round(time.Millisecond)),
+		Fields:  LogFields{Op: "summary"},
+		Summary: &stats,
+	})
 }
 
-// Close closes the log file
+// Close writes the session footer and closes the sink
 func (l *Logger) Close() error {
-	if l.file != nil {
-		l.logger.Println("=== Session Ended ===")
-		return l.file.Close()
+	if l == nil || l.sink == nil {
+		return nil
 	}
-	return nil
-}
-
-// Summary holds statistics about the organization process
-type Summary struct {
-	FilesScanned   int
-	FilesMoved     int
-	FoldersCreated int
-	FilesSkipped   int
+	l.Info("=== Session Ended ===", LogFields{})
+	return l.sink.Close()
 }
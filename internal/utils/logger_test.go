@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -136,9 +139,91 @@ func TestSummaryStruct(t *testing.T) {
 }
 
 func TestLoggerNilFile(t *testing.T) {
-	logger := &Logger{file: nil}
+	logger := &Logger{}
 
 	// Closing nil file should not panic
 	err := logger.Close()
 	assert.NoError(t, err)
 }
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+	for name, expected := range tests {
+		level, err := ParseLogLevel(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, expected, level, name)
+	}
+
+	_, err := ParseLogLevel("loud")
+	assert.Error(t, err)
+}
+
+func TestParseLogFormat(t *testing.T) {
+	format, err := ParseLogFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, LogFormatText, format)
+
+	format, err = ParseLogFormat("JSON")
+	assert.NoError(t, err)
+	assert.Equal(t, LogFormatJSON, format)
+
+	_, err = ParseLogFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestLoggerJSONFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWriterLogger(&buf, LoggerOptions{Format: LogFormatJSON})
+	logger.LogMoved(LogFields{Src: "file.pdf", Dst: "Documents/file.pdf", Category: "Documents", Bytes: 42})
+	logger.LogError("Move", "broken.txt", assert.AnError)
+	assert.NoError(t, logger.Close())
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	assert.Len(t, records, 4)
+
+	// Every record carries the session ID
+	for _, record := range records {
+		assert.Equal(t, logger.Session(), record["session"])
+	}
+
+	move := records[1]
+	assert.Equal(t, "INFO", move["level"])
+	assert.Equal(t, "move", move["op"])
+	assert.Equal(t, "file.pdf", move["src"])
+	assert.Equal(t, "Documents/file.pdf", move["dst"])
+	assert.Equal(t, "Documents", move["category"])
+	assert.Equal(t, float64(42), move["bytes"])
+
+	failure := records[2]
+	assert.Equal(t, "ERROR", failure["level"])
+	assert.Equal(t, "Move", failure["op"])
+	assert.Equal(t, assert.AnError.Error(), failure["err"])
+}
+
+func TestLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWriterLogger(&buf, LoggerOptions{Level: slog.LevelWarn})
+	logger.Debug("debug record", LogFields{})
+	logger.LogMove("file.pdf", "Documents/file.pdf")
+	logger.Warn("warning record", LogFields{Src: "file.pdf"})
+	logger.LogError("Move", "broken.txt", assert.AnError)
+
+	content := buf.String()
+	assert.NotContains(t, content, "debug record")
+	assert.NotContains(t, content, "[MOVE]")
+	assert.Contains(t, content, "level=WARN")
+	assert.Contains(t, content, "src=file.pdf")
+	assert.Contains(t, content, "level=ERROR")
+	assert.Contains(t, content, "session="+logger.Session())
+}
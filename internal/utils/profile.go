@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile is a named set of organization settings selected with --profile.
+// A profile may extend another profile, inheriting everything it does not override.
+type Profile struct {
+	Extends        string            `json:"extends,omitempty" yaml:"extends,omitempty" toml:"extends,omitempty"`
+	CustomMappings map[string]string `json:"customMappings,omitempty" yaml:"customMappings,omitempty" toml:"customMappings,omitempty"`
+	Rules          []Rule            `json:"rules,omitempty" yaml:"rules,omitempty" toml:"rules,omitempty"`
+	IgnorePatterns []string          `json:"ignorePatterns,omitempty" yaml:"ignorePatterns,omitempty" toml:"ignorePatterns,omitempty"`
+	Destination    string            `json:"destination,omitempty" yaml:"destination,omitempty" toml:"destination,omitempty"`
+	ConflictPolicy ConflictPolicy    `json:"conflictPolicy,omitempty" yaml:"conflictPolicy,omitempty" toml:"conflictPolicy,omitempty"`
+
+	origin string // describes the config file and profile a resolved profile came from
+}
+
+// Rule assigns a category to files whose name matches a glob pattern.
+// Rules take precedence over extension mappings.
+type Rule struct {
+	Pattern  string `json:"pattern" yaml:"pattern" toml:"pattern"`
+	Category string `json:"category" yaml:"category" toml:"category"`
+}
+
+// ConflictPolicy decides what happens when a file already exists at the destination
+type ConflictPolicy string
+
+const (
+	// ConflictError reports an error and leaves the file in place (the default)
+	ConflictError ConflictPolicy = "error"
+	// ConflictSkip leaves the file in place and counts it as skipped
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictRename moves the file under a numbered name such as "report (1).pdf"
+	ConflictRename ConflictPolicy = "rename"
+	// ConflictOverwrite replaces the existing destination file
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// ParseConflictPolicy validates a conflict policy name. An empty name selects ConflictError.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return ConflictError, nil
+	case ConflictError, ConflictSkip, ConflictRename, ConflictOverwrite:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy '%s', expected one of: error, skip, rename, overwrite", name)
+	}
+}
+
+// UnknownPolicy decides what happens to files whose category is "Unknown" or "No Extension"
+type UnknownPolicy string
+
+const (
+	// UnknownLeave leaves the files in place (the default)
+	UnknownLeave UnknownPolicy = "leave"
+	// UnknownMove moves the files into a single folder such as "Misc"
+	UnknownMove UnknownPolicy = "move"
+	// UnknownGroup moves the files into one folder per extension, e.g. "Other/.xyz"
+	UnknownGroup UnknownPolicy = "group"
+)
+
+// ParseUnknownPolicy validates an unknown file policy name. An empty name selects UnknownLeave.
+func ParseUnknownPolicy(name string) (UnknownPolicy, error) {
+	switch policy := UnknownPolicy(strings.ToLower(strings.TrimSpace(name))); policy {
+	case "":
+		return UnknownLeave, nil
+	case UnknownLeave, UnknownMove, UnknownGroup:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown file policy '%s', expected one of: leave, move, group", name)
+	}
+}
+
+// ProfileNames returns the names of all profiles defined in the config, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveProfile returns the named profile merged with the profiles it extends.
+// Settings from the named profile override those inherited from its base.
+func (c *Config) ResolveProfile(name string) (*Profile, error) {
+	// Collect the inheritance chain, starting from the requested profile
+	var chain []Profile
+	visited := make(map[string]bool)
+	for current := name; current != ""; {
+		if visited[current] {
+			return nil, fmt.Errorf("profile '%s' has an inheritance cycle through '%s'", name, current)
+		}
+		visited[current] = true
+
+		profile, exists := c.Profiles[current]
+		if !exists {
+			if current == name {
+				return nil, fmt.Errorf("profile '%s' is not defined in config", name)
+			}
+			return nil, fmt.Errorf("profile '%s' extends undefined profile '%s'", name, current)
+		}
+
+		chain = append(chain, profile)
+		current = profile.Extends
+	}
+
+	// Merge from the base profile down to the requested one
+	resolved := &Profile{CustomMappings: make(map[string]string)}
+	for i := len(chain) - 1; i >= 0; i-- {
+		profile := chain[i]
+		for ext, category := range profile.CustomMappings {
+			resolved.CustomMappings[ext] = category
+		}
+
+		// Rules from the more specific profile are checked first
+		resolved.Rules = append(append([]Rule{}, profile.Rules...), resolved.Rules...)
+		resolved.IgnorePatterns = append(resolved.IgnorePatterns, profile.IgnorePatterns...)
+
+		if profile.Destination != "" {
+			resolved.Destination = profile.Destination
+		}
+		if profile.ConflictPolicy != "" {
+			resolved.ConflictPolicy = profile.ConflictPolicy
+		}
+	}
+	resolved.Extends = ""
+	resolved.origin = fmt.Sprintf("%s (profile %s)", c.file, name)
+
+	if _, err := ParseConflictPolicy(string(resolved.ConflictPolicy)); err != nil {
+		return nil, fmt.Errorf("profile '%s': %v", name, err)
+	}
+
+	return resolved, nil
+}
+
+// ApplyProfile merges a resolved profile's mappings and rules into the extension mapping
+func (em *ExtensionMapping) ApplyProfile(profile *Profile) error {
+	for ext, category := range profile.CustomMappings {
+		if err := em.validateExtension(ext); err != nil {
+			return fmt.Errorf("invalid extension '%s' in profile: %v", ext, err)
+		}
+		if err := em.validateCategory(category); err != nil {
+			return fmt.Errorf("invalid category '%s' for extension '%s' in profile: %v", category, ext, err)
+		}
+
+		em.mappings[strings.ToLower(ext)] = category
+		em.sources[strings.ToLower(ext)] = "config"
+		em.origins[strings.ToLower(ext)] = profile.origin
+	}
+
+	return em.AddRules(profile.Rules)
+}
+
+// AddRules adds filename rules, which are checked before extension mappings
+func (em *ExtensionMapping) AddRules(rules []Rule) error {
+	for _, rule := range rules {
+		if err := validateRulePattern(rule.Pattern); err != nil {
+			return err
+		}
+		if err := em.validateCategory(rule.Category); err != nil {
+			return fmt.Errorf("invalid category '%s' for rule '%s': %v", rule.Category, rule.Pattern, err)
+		}
+		em.rules = append(em.rules, rule)
+	}
+	return nil
+}
+
+// GetRules returns the filename rules in the order they are checked
+func (em *ExtensionMapping) GetRules() []Rule {
+	result := make([]Rule, len(em.rules))
+	copy(result, em.rules)
+	return result
+}
+
+// GetCategory returns the category for a file name, checking filename rules
+// first and falling back to the extension mapping
+func (em *ExtensionMapping) GetCategory(fileName string) (string, bool) {
+	name := strings.ToLower(filepath.Base(fileName))
+	for _, rule := range em.rules {
+		if matched, _ := filepath.Match(strings.ToLower(rule.Pattern), name); matched {
+			return rule.Category, true
+		}
+	}
+
+	return em.GetMapping(filepath.Ext(name))
+}
+
+// validateRulePattern checks that a rule pattern is a valid glob
+func validateRulePattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("rule pattern cannot be empty")
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid rule pattern '%s': %v", pattern, err)
+	}
+	return nil
+}
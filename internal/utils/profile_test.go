@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveProfileInheritance(t *testing.T) {
+	config := &Config{
+		Profiles: map[string]Profile{
+			"base": {
+				CustomMappings: map[string]string{".md": "Notes", ".log": "Logs"},
+				Rules:          []Rule{{Pattern: "invoice*", Category: "Invoices"}},
+				IgnorePatterns: []string{"*.tmp"},
+				Destination:    "Sorted",
+				ConflictPolicy: ConflictSkip,
+			},
+			"screenshots": {
+				Extends:        "base",
+				CustomMappings: map[string]string{".md": "Docs"},
+				Rules:          []Rule{{Pattern: "Screenshot*", Category: "Screenshots"}},
+				IgnorePatterns: []string{"*.part"},
+				ConflictPolicy: ConflictRename,
+			},
+		},
+	}
+
+	profile, err := config.ResolveProfile("screenshots")
+	assert.NoError(t, err)
+
+	// Child mappings override the base, inherited ones are kept
+	assert.Equal(t, "Docs", profile.CustomMappings[".md"])
+	assert.Equal(t, "Logs", profile.CustomMappings[".log"])
+
+	// Child rules are checked before inherited rules
+	assert.Equal(t, []Rule{
+		{Pattern: "Screenshot*", Category: "Screenshots"},
+		{Pattern: "invoice*", Category: "Invoices"},
+	}, profile.Rules)
+
+	assert.Equal(t, []string{"*.tmp", "*.part"}, profile.IgnorePatterns)
+	assert.Equal(t, "Sorted", profile.Destination)
+	assert.Equal(t, ConflictRename, profile.ConflictPolicy)
+	assert.Empty(t, profile.Extends)
+
+	assert.Equal(t, []string{"base", "screenshots"}, config.ProfileNames())
+}
+
+func TestResolveProfileErrors(t *testing.T) {
+	config := &Config{
+		Profiles: map[string]Profile{
+			"a":       {Extends: "b"},
+			"b":       {Extends: "a"},
+			"orphan":  {Extends: "missing"},
+			"invalid": {ConflictPolicy: "explode"},
+		},
+	}
+
+	_, err := config.ResolveProfile("a")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "inheritance cycle")
+
+	_, err = config.ResolveProfile("orphan")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "extends undefined profile 'missing'")
+
+	_, err = config.ResolveProfile("nope")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is not defined in config")
+
+	_, err = config.ResolveProfile("invalid")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown conflict policy")
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	policy, err := ParseConflictPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictError, policy)
+
+	policy, err = ParseConflictPolicy("Rename")
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictRename, policy)
+
+	_, err = ParseConflictPolicy("merge")
+	assert.Error(t, err)
+}
+
+func TestParseUnknownPolicy(t *testing.T) {
+	policy, err := ParseUnknownPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, UnknownLeave, policy)
+
+	policy, err = ParseUnknownPolicy(" Group ")
+	assert.NoError(t, err)
+	assert.Equal(t, UnknownGroup, policy)
+
+	_, err = ParseUnknownPolicy("delete")
+	assert.Error(t, err)
+}
+
+func TestApplyProfileAndGetCategory(t *testing.T) {
+	mapping := NewExtensionMapping(map[string]string{".png": "Images", ".txt": "Documents"})
+
+	err := mapping.ApplyProfile(&Profile{
+		CustomMappings: map[string]string{".txt": "Notes"},
+		Rules:          []Rule{{Pattern: "Screenshot*.png", Category: "Screenshots"}},
+	})
+	assert.NoError(t, err)
+
+	// Rules win over extension mappings and are case-insensitive
+	category, exists := mapping.GetCategory("/tmp/screenshot 2024-01-01.PNG")
+	assert.True(t, exists)
+	assert.Equal(t, "Screenshots", category)
+
+	category, exists = mapping.GetCategory("holiday.png")
+	assert.True(t, exists)
+	assert.Equal(t, "Images", category)
+
+	category, exists = mapping.GetCategory("todo.txt")
+	assert.True(t, exists)
+	assert.Equal(t, "Notes", category)
+
+	_, exists = mapping.GetCategory("Makefile")
+	assert.False(t, exists)
+
+	// Invalid rules are rejected
+	err = mapping.AddRules([]Rule{{Pattern: "[", Category: "Broken"}})
+	assert.Error(t, err)
+	err = mapping.AddRules([]Rule{{Pattern: "*.x", Category: "a/b"}})
+	assert.Error(t, err)
+}
+
+func TestLoadProfilesFromYAML(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "profile-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := `customMappings:
+  .md: Notes
+profiles:
+  base:
+    ignorePatterns: ["*.part"]
+    conflictPolicy: rename
+  downloads:
+    extends: base
+    destination: Sorted
+    rules:
+      - pattern: "invoice*"
+        category: Invoices
+`
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	config, err := ReadConfigFile(configPath)
+	assert.NoError(t, err)
+
+	profile, err := config.ResolveProfile("downloads")
+	assert.NoError(t, err)
+	assert.Equal(t, "Sorted", profile.Destination)
+	assert.Equal(t, ConflictRename, profile.ConflictPolicy)
+	assert.Equal(t, []string{"*.part"}, profile.IgnorePatterns)
+	assert.Equal(t, []Rule{{Pattern: "invoice*", Category: "Invoices"}}, profile.Rules)
+
+	// Profiles are understood by the strict validator too
+	assert.Empty(t, ValidateConfigFiles(nil, []string{configPath}, "", nil))
+}
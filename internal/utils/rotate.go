@@ -0,0 +1,288 @@
+package utils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AppName is the directory name used for the application's state files
+const AppName = "go-file-organizer"
+
+// backupTimeLayout is the timestamp in the names of rotated log files
+const backupTimeLayout = "20060102-150405.000"
+
+// DefaultStateDir returns the directory for the application's state files:
+// $XDG_STATE_HOME/go-file-organizer, falling back to ~/.local/state (or
+// %LOCALAPPDATA% on Windows) and finally to the current directory.
+func DefaultStateDir() string {
+	if stateHome := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(stateHome) {
+		return filepath.Join(stateHome, AppName)
+	}
+	if runtime.GOOS == "windows" {
+		if localAppData := os.Getenv("LOCALAPPDATA"); localAppData != "" {
+			return filepath.Join(localAppData, AppName)
+		}
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", AppName)
+	}
+	return "."
+}
+
+// DefaultLogPath returns the default log file location, organizer.log in the
+// state directory
+func DefaultLogPath() string {
+	return filepath.Join(DefaultStateDir(), "organizer.log")
+}
+
+// RotateOptions configures when a log file is rotated and which rotated files are kept
+type RotateOptions struct {
+	// MaxSize is the size in bytes at which the file is rotated (0 to never rotate)
+	MaxSize int64
+	// MaxBackups is the number of rotated files kept (0 to keep all)
+	MaxBackups int
+	// MaxAge is the age at which the file is rotated, and rotated files older
+	// than this are removed (0 to rotate by size only and keep them regardless of age)
+	MaxAge time.Duration
+	// Compress gzips rotated files
+	Compress bool
+}
+
+// RotatingFile is an append-only file that is renamed to a timestamped backup
+// once it reaches its maximum size or age. It is safe for concurrent use.
+type RotatingFile struct {
+	mu     sync.Mutex
+	path   string
+	opts   RotateOptions
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenRotatingFile opens a log file for appending, creating it and its
+// directory if needed. An existing file that is already too large, or was last
+// written longer than MaxAge ago, is rotated.
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
+	}
+
+	rf := &RotatingFile{path: path, opts: opts}
+	modTime, err := rf.open()
+	if err != nil {
+		return nil, err
+	}
+	tooOld := opts.MaxAge > 0 && rf.size > 0 && time.Since(modTime) >= opts.MaxAge
+	if tooOld || (opts.MaxSize > 0 && rf.size >= opts.MaxSize) {
+		if err := rf.rotate(); err != nil {
+			rf.Close()
+			return nil, err
+		}
+	}
+	return rf, nil
+}
+
+// open opens the current file in append mode and returns when it was last written
+func (rf *RotatingFile) open() (time.Time, error) {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to open log file: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return time.Time{}, fmt.Errorf("failed to open log file: %v", err)
+	}
+	rf.file = file
+	rf.size = info.Size()
+	rf.opened = time.Now()
+	return info.ModTime(), nil
+}
+
+// Write appends to the file, rotating it first if the write would exceed the
+// maximum size or the file was opened longer than MaxAge ago
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return 0, fmt.Errorf("log file %s is closed", rf.path)
+	}
+	tooLarge := rf.opts.MaxSize > 0 && rf.size+int64(len(p)) > rf.opts.MaxSize
+	tooOld := rf.opts.MaxAge > 0 && time.Since(rf.opened) >= rf.opts.MaxAge
+	if rf.size > 0 && (tooLarge || tooOld) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Close closes the file
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
+
+// rotate renames the current file to a timestamped backup, starts a new file
+// and removes the backups that are no longer retained. If the file cannot be
+// renamed it is reopened, so that logging carries on.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %v", err)
+	}
+	rf.file = nil
+
+	backup := rf.backupName(time.Now())
+	if err := os.Rename(rf.path, backup); err != nil {
+		if _, openErr := rf.open(); openErr != nil {
+			return fmt.Errorf("failed to rotate log file: %v; %v", err, openErr)
+		}
+		return fmt.Errorf("failed to rotate log file: %v", err)
+	}
+	if _, err := rf.open(); err != nil {
+		return err
+	}
+
+	if rf.opts.Compress {
+		if err := compressFile(backup); err != nil {
+			return fmt.Errorf("failed to compress rotated log file: %v", err)
+		}
+	}
+	return rf.prune()
+}
+
+// backupName returns an unused name for a backup rotated at the given time,
+// e.g. organizer-20250621-100000.000.log
+func (rf *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(rf.path)
+	for {
+		name := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(rf.path, ext), t.Format(backupTimeLayout), ext)
+		if !fileExists(name) && !fileExists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// fileExists reports whether a file exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// backup is a rotated log file
+type backup struct {
+	path    string
+	rotated time.Time
+}
+
+// backups lists the rotated files of this log, newest first
+func (rf *RotatingFile) backups() ([]backup, error) {
+	dir := filepath.Dir(rf.path)
+	ext := filepath.Ext(rf.path)
+	prefix := strings.TrimSuffix(filepath.Base(rf.path), ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotated log files: %v", err)
+	}
+
+	var found []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(strings.TrimSuffix(name, ".gz"), prefix)
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+		rotated, err := time.ParseInLocation(backupTimeLayout, strings.TrimSuffix(stamp, ext), time.Local)
+		if err != nil {
+			continue // Not one of ours
+		}
+		found = append(found, backup{path: filepath.Join(dir, name), rotated: rotated})
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].rotated.After(found[j].rotated)
+	})
+	return found, nil
+}
+
+// prune removes the backups beyond MaxBackups and those older than MaxAge
+func (rf *RotatingFile) prune() error {
+	if rf.opts.MaxBackups <= 0 && rf.opts.MaxAge <= 0 {
+		return nil
+	}
+
+	backups, err := rf.backups()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-rf.opts.MaxAge)
+	for i, b := range backups {
+		tooMany := rf.opts.MaxBackups > 0 && i >= rf.opts.MaxBackups
+		tooOld := rf.opts.MaxAge > 0 && b.rotated.Before(cutoff)
+		if tooMany || tooOld {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove old log file: %v", err)
+			}
+		}
+	}
+	return nil
+}
+
+// compressFile replaces a file with a gzipped copy named file.gz
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rotatedFiles lists the rotated copies of a log file in its directory
+func rotatedFiles(t *testing.T, logPath string) []string {
+	matches, err := filepath.Glob(strings.TrimSuffix(logPath, ".log") + "-*")
+	assert.NoError(t, err)
+	return matches
+}
+
+func TestDefaultLogPath(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+	assert.Equal(t, filepath.Join(stateHome, AppName, "organizer.log"), DefaultLogPath())
+	assert.Equal(t, filepath.Join(stateHome, AppName), DefaultStateDir())
+
+	// Relative values are ignored, as required by the XDG specification
+	t.Setenv("XDG_STATE_HOME", "relative/state")
+	assert.NotContains(t, DefaultLogPath(), "relative")
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "logs", "organizer.log")
+
+	rf, err := OpenRotatingFile(logPath, RotateOptions{MaxSize: 100})
+	assert.NoError(t, err)
+
+	line := strings.Repeat("a", 59) + "\n"
+	for i := 0; i < 3; i++ {
+		n, err := rf.Write([]byte(line))
+		assert.NoError(t, err)
+		assert.Equal(t, len(line), n)
+	}
+	assert.NoError(t, rf.Close())
+
+	// Every write after the first would exceed the limit
+	assert.Len(t, rotatedFiles(t, logPath), 2)
+	content, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.Equal(t, line, string(content))
+}
+
+func TestRotatingFileRotatesLargeFileOnOpen(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "organizer.log")
+	assert.NoError(t, os.WriteFile(logPath, []byte(strings.Repeat("a", 200)), 0644))
+
+	rf, err := OpenRotatingFile(logPath, RotateOptions{MaxSize: 100})
+	assert.NoError(t, err)
+	assert.NoError(t, rf.Close())
+
+	assert.Len(t, rotatedFiles(t, logPath), 1)
+	info, err := os.Stat(logPath)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size())
+}
+
+func TestRotatingFileRotatesByAge(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "organizer.log")
+
+	rf, err := OpenRotatingFile(logPath, RotateOptions{MaxAge: time.Hour})
+	assert.NoError(t, err)
+	_, err = rf.Write([]byte("first\n"))
+	assert.NoError(t, err)
+	_, err = rf.Write([]byte("second\n"))
+	assert.NoError(t, err)
+	assert.Empty(t, rotatedFiles(t, logPath))
+
+	// A file open for longer than MaxAge is rotated on the next write
+	rf.opened = time.Now().Add(-2 * time.Hour)
+	_, err = rf.Write([]byte("third\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, rf.Close())
+
+	assert.Len(t, rotatedFiles(t, logPath), 1)
+	content, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "third\n", string(content))
+
+	// So is an existing file last written longer than MaxAge ago
+	old := time.Now().Add(-2 * time.Hour)
+	assert.NoError(t, os.Chtimes(logPath, old, old))
+	rf, err = OpenRotatingFile(logPath, RotateOptions{MaxAge: time.Hour})
+	assert.NoError(t, err)
+	assert.NoError(t, rf.Close())
+	assert.Len(t, rotatedFiles(t, logPath), 2)
+}
+
+func TestRotatingFileRenameFailure(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "organizer.log")
+
+	rf, err := OpenRotatingFile(logPath, RotateOptions{MaxSize: 10})
+	assert.NoError(t, err)
+	defer rf.Close()
+	_, err = rf.Write([]byte("first line\n"))
+	assert.NoError(t, err)
+
+	// The file is gone, so it cannot be renamed when it is rotated
+	assert.NoError(t, os.Remove(logPath))
+	_, err = rf.Write([]byte("second\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to rotate log file")
+
+	// Logging carries on in the reopened file
+	_, err = rf.Write([]byte("third\n"))
+	assert.NoError(t, err)
+	content, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "third\n", string(content))
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "organizer.log")
+
+	// A backup rotated long ago and an unrelated file with a similar name
+	oldBackup := filepath.Join(tempDir, "organizer-20000101-000000.000.log")
+	unrelated := filepath.Join(tempDir, "organizer-notes.log")
+	assert.NoError(t, os.WriteFile(oldBackup, []byte("old"), 0644))
+	assert.NoError(t, os.WriteFile(unrelated, []byte("mine"), 0644))
+
+	rf, err := OpenRotatingFile(logPath, RotateOptions{MaxSize: 10, MaxBackups: 2, MaxAge: 24 * time.Hour})
+	assert.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := rf.Write([]byte("0123456789"))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, rf.Close())
+
+	backups := rotatedFiles(t, logPath)
+	assert.Len(t, backups, 3)
+	assert.Contains(t, backups, unrelated)
+	assert.NoFileExists(t, oldBackup)
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "organizer.log")
+
+	rf, err := OpenRotatingFile(logPath, RotateOptions{MaxSize: 10, Compress: true})
+	assert.NoError(t, err)
+	_, err = rf.Write([]byte("first run\n"))
+	assert.NoError(t, err)
+	_, err = rf.Write([]byte("second run\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, rf.Close())
+
+	backups := rotatedFiles(t, logPath)
+	if assert.Len(t, backups, 1) {
+		assert.True(t, strings.HasSuffix(backups[0], ".log.gz"))
+
+		file, err := os.Open(backups[0])
+		assert.NoError(t, err)
+		defer file.Close()
+		reader, err := gzip.NewReader(file)
+		assert.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, "first run\n", string(content))
+	}
+}
@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of the environment variables that override settings
+const EnvPrefix = "GFO_"
+
+// Setting sources, from lowest to highest precedence
+const (
+	SourceDefault = "default"
+	SourceConfig  = "config"
+	SourceEnv     = "env"
+	SourceCLI     = "cli"
+)
+
+// sourcePrecedence orders the setting sources: default < config file < env < CLI
+var sourcePrecedence = map[string]int{
+	SourceDefault: 0,
+	SourceConfig:  1,
+	SourceEnv:     2,
+	SourceCLI:     3,
+}
+
+// Settings holds run settings gathered from several sources.
+// A value only replaces an existing one if it comes from a source with equal
+// or higher precedence, so sources can be applied in any order.
+type Settings struct {
+	values  map[string]string
+	sources map[string]string
+}
+
+// NewSettings creates an empty settings store
+func NewSettings() *Settings {
+	return &Settings{
+		values:  make(map[string]string),
+		sources: make(map[string]string),
+	}
+}
+
+// EnvName returns the environment variable for a setting, e.g. "dry-run" becomes "GFO_DRY_RUN"
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Set records a value for a setting unless a higher precedence source already set it
+func (s *Settings) Set(name, value, source string) {
+	if current, exists := s.sources[name]; exists && sourcePrecedence[current] > sourcePrecedence[source] {
+		return
+	}
+	s.values[name] = value
+	s.sources[name] = source
+}
+
+// ApplyEnv sets every named setting that has a matching GFO_* environment variable
+func (s *Settings) ApplyEnv(names []string, lookupEnv func(string) (string, bool)) {
+	for _, name := range names {
+		if value, exists := lookupEnv(EnvName(name)); exists {
+			s.Set(name, value, SourceEnv)
+		}
+	}
+}
+
+// ApplyConfig sets the settings defined at the top level of a config file
+func (s *Settings) ApplyConfig(config *Config) {
+	if config == nil {
+		return
+	}
+
+	setString := func(name, value string) {
+		if value != "" {
+			s.Set(name, value, SourceConfig)
+		}
+	}
+	setBool := func(name string, value *bool) {
+		if value != nil {
+			s.Set(name, strconv.FormatBool(*value), SourceConfig)
+		}
+	}
+	setInt := func(name string, value *int) {
+		if value != nil {
+			s.Set(name, strconv.Itoa(*value), SourceConfig)
+		}
+	}
+
+	setString("path", config.Path)
+	setBool("dry-run", config.DryRun)
+	setBool("progress", config.Progress)
+	setBool("watch", config.Watch)
+	setString("profile", config.Profile)
+	setString("ignore-file", config.IgnoreFile)
+	setString("log-file", config.LogFile)
+	setString("log-level", config.LogLevel)
+	setString("log-format", config.LogFormat)
+	setBool("no-log", config.NoLog)
+	setInt("log-max-size", config.LogMaxSize)
+	setInt("log-max-backups", config.LogMaxBackups)
+	setInt("log-max-age", config.LogMaxAge)
+	setBool("log-compress", config.LogCompress)
+	setString("output", config.Output)
+	setString("unknown", config.UnknownPolicy)
+	setString("unknown-folder", config.UnknownFolder)
+	setBool("sniff", config.SniffContent)
+	setString("skip-category", strings.Join(config.SkipCategories, ","))
+}
+
+// String returns the effective value of a setting
+func (s *Settings) String(name string) string {
+	return s.values[name]
+}
+
+// Bool returns the effective value of a boolean setting
+func (s *Settings) Bool(name string) (bool, error) {
+	value := s.values[name]
+	if value == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value '%s' for %s (from %s)", value, name, s.describeSource(name))
+	}
+	return parsed, nil
+}
+
+// Int returns the effective value of a non-negative integer setting
+func (s *Settings) Int(name string) (int, error) {
+	value := s.values[name]
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid value '%s' for %s (from %s), expected a non-negative integer", value, name, s.describeSource(name))
+	}
+	return parsed, nil
+}
+
+// List returns the effective value of a comma separated setting, with empty items removed
+func (s *Settings) List(name string) []string {
+	var items []string
+	for _, item := range strings.Split(s.values[name], ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Source returns where the effective value of a setting came from
+func (s *Settings) Source(name string) string {
+	return s.sources[name]
+}
+
+// Names returns the names of all known settings, sorted
+func (s *Settings) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describeSource names the origin of a setting for error messages
+func (s *Settings) describeSource(name string) string {
+	if s.sources[name] == SourceEnv {
+		return EnvName(name)
+	}
+	return s.sources[name]
+}
+
+// ApplyEnvMappings applies mapping overrides from the GFO_MAP environment variable.
+// Mappings are separated by commas or semicolons, e.g. ".md=Notes,.log=Logs".
+func (em *ExtensionMapping) ApplyEnvMappings(value string) error {
+	count := 0
+	for _, mapping := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		mapping = strings.TrimSpace(mapping)
+		if mapping == "" {
+			continue
+		}
+
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid mapping format '%s' in %s, expected '.ext=Category'", mapping, EnvName("map"))
+		}
+
+		ext := strings.TrimSpace(parts[0])
+		category := strings.TrimSpace(parts[1])
+
+		if err := em.validateExtension(ext); err != nil {
+			return fmt.Errorf("invalid extension '%s' in %s: %v", ext, EnvName("map"), err)
+		}
+
+		if err := em.validateCategory(category); err != nil {
+			return fmt.Errorf("invalid category '%s' in %s: %v", category, EnvName("map"), err)
+		}
+
+		em.mappings[strings.ToLower(ext)] = category
+		em.sources[strings.ToLower(ext)] = SourceEnv
+		em.origins[strings.ToLower(ext)] = EnvName("map")
+		count++
+	}
+
+	if count > 0 {
+		fmt.Fprintf(em.output, "Applied %d environment mapping overrides\n", count)
+	}
+	return nil
+}
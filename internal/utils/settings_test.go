@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "GFO_PATH", EnvName("path"))
+	assert.Equal(t, "GFO_DRY_RUN", EnvName("dry-run"))
+	assert.Equal(t, "GFO_IGNORE_FILE", EnvName("ignore-file"))
+}
+
+func TestSettingsPrecedence(t *testing.T) {
+	dryRun := true
+	config := &Config{Path: "/from/config", DryRun: &dryRun, LogFile: "config.log"}
+	env := map[string]string{
+		"GFO_PATH":     "/from/env",
+		"GFO_LOG_FILE": "env.log",
+	}
+	lookupEnv := func(name string) (string, bool) {
+		value, exists := env[name]
+		return value, exists
+	}
+
+	settings := NewSettings()
+	settings.Set("path", "", SourceDefault)
+	settings.Set("dry-run", "false", SourceDefault)
+	settings.Set("log-file", "organizer.log", SourceDefault)
+	settings.Set("ignore-file", ".organizerignore", SourceDefault)
+
+	// CLI is applied first on purpose: lower precedence sources must not override it
+	settings.Set("log-file", "cli.log", SourceCLI)
+	settings.ApplyEnv([]string{"path", "dry-run", "log-file", "ignore-file"}, lookupEnv)
+	settings.ApplyConfig(config)
+
+	assert.Equal(t, "/from/env", settings.String("path"))
+	assert.Equal(t, SourceEnv, settings.Source("path"))
+
+	value, err := settings.Bool("dry-run")
+	assert.NoError(t, err)
+	assert.True(t, value)
+	assert.Equal(t, SourceConfig, settings.Source("dry-run"))
+
+	assert.Equal(t, "cli.log", settings.String("log-file"))
+	assert.Equal(t, SourceCLI, settings.Source("log-file"))
+
+	assert.Equal(t, ".organizerignore", settings.String("ignore-file"))
+	assert.Equal(t, SourceDefault, settings.Source("ignore-file"))
+
+	assert.Equal(t, []string{"dry-run", "ignore-file", "log-file", "path"}, settings.Names())
+}
+
+func TestSettingsInvalidBool(t *testing.T) {
+	settings := NewSettings()
+	settings.Set("watch", "sometimes", SourceEnv)
+
+	_, err := settings.Bool("watch")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "GFO_WATCH")
+}
+
+func TestSettingsUnknownFileConfig(t *testing.T) {
+	sniff := true
+	config := &Config{UnknownPolicy: "group", SniffContent: &sniff, SkipCategories: []string{"Screenshots", "Code"}}
+
+	settings := NewSettings()
+	settings.Set("skip-category", "", SourceDefault)
+	settings.ApplyConfig(config)
+
+	assert.Equal(t, "group", settings.String("unknown"))
+	value, err := settings.Bool("sniff")
+	assert.NoError(t, err)
+	assert.True(t, value)
+	assert.Equal(t, []string{"Screenshots", "Code"}, settings.List("skip-category"))
+
+	settings.Set("skip-category", " Logs, ,Temp ", SourceCLI)
+	assert.Equal(t, []string{"Logs", "Temp"}, settings.List("skip-category"))
+	assert.Empty(t, settings.List("undefined"))
+}
+
+func TestApplyEnvMappings(t *testing.T) {
+	mapping := NewExtensionMapping(map[string]string{".txt": "Documents"})
+
+	err := mapping.ApplyEnvMappings(".md=Notes, .txt=Text;.log=Logs")
+	assert.NoError(t, err)
+
+	category, _ := mapping.GetMapping(".md")
+	assert.Equal(t, "Notes", category)
+	category, _ = mapping.GetMapping(".txt")
+	assert.Equal(t, "Text", category)
+	category, _ = mapping.GetMapping(".log")
+	assert.Equal(t, "Logs", category)
+	assert.Equal(t, SourceEnv, mapping.sources[".md"])
+
+	// CLI mappings still win over environment mappings
+	err = mapping.ApplyCLIMappings([]string{".md=Docs"})
+	assert.NoError(t, err)
+	category, _ = mapping.GetMapping(".md")
+	assert.Equal(t, "Docs", category)
+
+	err = mapping.ApplyEnvMappings("md=Notes")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "GFO_MAP")
+}
+
+func TestSettingsLogRotationConfig(t *testing.T) {
+	maxSize, maxBackups := 20, 3
+	noLog, compress := true, true
+	config := &Config{LogMaxSize: &maxSize, LogMaxBackups: &maxBackups, NoLog: &noLog, LogCompress: &compress}
+
+	settings := NewSettings()
+	settings.Set("log-max-size", "10", SourceDefault)
+	settings.Set("log-max-age", "0", SourceDefault)
+	settings.ApplyConfig(config)
+
+	size, err := settings.Int("log-max-size")
+	assert.NoError(t, err)
+	assert.Equal(t, 20, size)
+	backups, err := settings.Int("log-max-backups")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, backups)
+	age, err := settings.Int("log-max-age")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, age)
+
+	enabled, err := settings.Bool("no-log")
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	settings.Set("log-max-age", "-1", SourceEnv)
+	_, err = settings.Int("log-max-age")
+	assert.ErrorContains(t, err, EnvName("log-max-age"))
+}
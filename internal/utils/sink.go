@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// LogRecord is a single entry of the operation log
+type LogRecord struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Session is the ID of the run that wrote the record
+	Session string
+	Fields  LogFields
+	// Summary is set on the record written at the end of a run
+	Summary *Summary
+}
+
+// LogSink receives the operation log of a run. The organizer writes to a sink
+// through a Logger; library callers can plug in their own implementation.
+type LogSink interface {
+	// Handle receives a record
+	Handle(record LogRecord)
+	// Close releases the sink's resources
+	Close() error
+}
+
+// NopSink discards every record
+type NopSink struct{}
+
+// Handle discards the record
+func (NopSink) Handle(LogRecord) {}
+
+// Close does nothing
+func (NopSink) Close() error { return nil }
+
+// MemorySink keeps every record in memory. It is safe for concurrent use.
+type MemorySink struct {
+	mu      sync.Mutex
+	records []LogRecord
+}
+
+// NewMemorySink creates an empty in-memory sink
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Handle stores the record
+func (m *MemorySink) Handle(record LogRecord) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, record)
+}
+
+// Records returns a copy of the stored records, in the order they were received
+func (m *MemorySink) Records() []LogRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]LogRecord(nil), m.records...)
+}
+
+// Close does nothing; the records stay available
+func (m *MemorySink) Close() error { return nil }
+
+// FileSink writes records as slog text or JSON to a file or writer
+type FileSink struct {
+	handler slog.Handler
+	closer  io.Closer
+}
+
+// NewFileSink creates a sink that appends to a log file, creating its directory
+// if needed, and rotates it as configured
+func NewFileSink(logPath string, opts LoggerOptions) (*FileSink, error) {
+	file, err := OpenRotatingFile(logPath, opts.Rotate)
+	if err != nil {
+		return nil, err
+	}
+	sink := NewWriterSink(file, opts)
+	sink.closer = file
+	return sink, nil
+}
+
+// NewWriterSink creates a sink that writes to w, which is not closed by Close
+func NewWriterSink(w io.Writer, opts LoggerOptions) *FileSink {
+	handlerOptions := &slog.HandlerOptions{Level: opts.Level}
+	if opts.Format == LogFormatJSON {
+		return &FileSink{handler: slog.NewJSONHandler(w, handlerOptions)}
+	}
+	return &FileSink{handler: slog.NewTextHandler(w, handlerOptions)}
+}
+
+// Handle writes the record if its level is enabled
+func (f *FileSink) Handle(record LogRecord) {
+	ctx := context.Background()
+	if !f.handler.Enabled(ctx, record.Level) {
+		return
+	}
+
+	r := slog.NewRecord(record.Time, record.Level, record.Message, 0)
+	if record.Session != "" {
+		r.AddAttrs(slog.String("session", record.Session))
+	}
+	r.AddAttrs(record.Fields.attrs()...)
+	if stats := record.Summary; stats != nil {
+		r.AddAttrs(
+			slog.Int("scanned", stats.FilesScanned),
+			slog.Int("moved", stats.FilesMoved),
+			slog.Int("folders", stats.FoldersCreated),
+			slog.Int("skipped", stats.FilesSkipped),
+			slog.Int("ignored", stats.SkippedByIgnore),
+			slog.Int("failed", len(stats.Failures)),
+			slog.Int64("bytes", stats.BytesMoved),
+			slog.Duration("duration", stats.Duration),
+		)
+	}
+	f.handler.Handle(ctx, r)
+}
+
+// Close closes the log file, if the sink opened one
+func (f *FileSink) Close() error {
+	if f.closer != nil {
+		return f.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNilLogger(t *testing.T) {
+	var logger *Logger
+
+	// Every method of the nil logger is a no-op
+	assert.NotPanics(t, func() {
+		logger.LogDryRun("a.txt", "Documents/a.txt")
+		logger.LogMove("a.txt", "Documents/a.txt")
+		logger.LogFolderCreation("Documents", false)
+		logger.LogError("Move", "a.txt", assert.AnError)
+		logger.LogSummary(Summary{FilesScanned: 1})
+		logger.Debug("debug", LogFields{})
+		assert.Empty(t, logger.Session())
+		assert.NoError(t, logger.Close())
+	})
+}
+
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink()
+	logger := NewSinkLogger(sink)
+	logger.LogMoved(LogFields{Src: "a.txt", Dst: "Documents/a.txt", Category: "Documents", Bytes: 3})
+	logger.LogError("Move", "b.txt", assert.AnError)
+	logger.LogSummary(Summary{FilesScanned: 2, FilesMoved: 1})
+	assert.NoError(t, logger.Close())
+
+	records := sink.Records()
+	assert.Len(t, records, 5)
+	for _, record := range records {
+		assert.Equal(t, logger.Session(), record.Session)
+		assert.False(t, record.Time.IsZero())
+	}
+
+	assert.Equal(t, "move", records[1].Fields.Op)
+	assert.Equal(t, "Documents", records[1].Fields.Category)
+	assert.Equal(t, int64(3), records[1].Fields.Bytes)
+	assert.Equal(t, slog.LevelError, records[2].Level)
+	assert.Equal(t, assert.AnError, records[2].Fields.Err)
+	if assert.NotNil(t, records[3].Summary) {
+		assert.Equal(t, 2, records[3].Summary.FilesScanned)
+	}
+	assert.Equal(t, "=== Session Ended ===", records[4].Message)
+}
+
+func TestAsLogger(t *testing.T) {
+	assert.Nil(t, AsLogger(nil))
+
+	logger := NewSinkLogger(NopSink{})
+	assert.Same(t, logger, AsLogger(logger))
+
+	// Other sinks are wrapped without a session header
+	sink := NewMemorySink()
+	wrapped := AsLogger(sink)
+	assert.NotEmpty(t, wrapped.Session())
+	assert.Empty(t, sink.Records())
+	wrapped.LogMove("a.txt", "Documents/a.txt")
+	assert.Len(t, sink.Records(), 1)
+}
+
+func TestWriterSinkSummary(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewWriterLogger(&buf, LoggerOptions{})
+	logger.LogSummary(Summary{FilesScanned: 4, FilesMoved: 3, BytesMoved: 1024})
+
+	content := buf.String()
+	assert.Contains(t, content, "op=summary")
+	assert.Contains(t, content, "scanned=4")
+	assert.Contains(t, content, "moved=3")
+	assert.Contains(t, content, "bytes=1024")
+}
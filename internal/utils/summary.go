@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary holds statistics about the organization process
+type Summary struct {
+	FilesScanned   int `json:"filesScanned"`
+	FilesMoved     int `json:"filesMoved"`
+	FoldersCreated int `json:"foldersCreated"`
+	// FilesSkipped counts scanned files left in place, by category, by conflict
+	// or in review
+	FilesSkipped int `json:"filesSkipped"`
+
+	// SkippedByIgnore counts files matched by an ignore pattern; they are not scanned
+	SkippedByIgnore int `json:"skippedByIgnore"`
+	// DirectoriesIgnored counts directories matched by an ignore pattern, whose
+	// contents are not visited
+	DirectoriesIgnored int `json:"directoriesIgnored"`
+	// SkippedByCategory counts files left in place because of their category
+	SkippedByCategory int `json:"skippedByCategory"`
+	// SkippedByConflict counts files left in place because the destination exists
+	SkippedByConflict int `json:"skippedByConflict"`
+	// SkippedByReview counts files rejected in an interactive review
+	SkippedByReview int `json:"skippedByReview"`
+	// AlreadyOrganized counts files that are already in their category folder
+	AlreadyOrganized int `json:"alreadyOrganized"`
+	// ConflictsResolved counts files moved under a new name or over an existing file
+	ConflictsResolved int `json:"conflictsResolved"`
+
+	// BytesMoved is the total size of the moved files
+	BytesMoved int64 `json:"bytesMoved"`
+	// Categories holds the moved files and bytes per category folder
+	Categories map[string]*CategoryStats `json:"categories"`
+	// Extensions counts the scanned files per lower-case extension ("" for none)
+	Extensions map[string]int `json:"extensions"`
+	// Failures lists every file or folder that could not be processed
+	Failures []Failure `json:"failures"`
+
+	// Duration is the wall-clock time of the run
+	Duration time.Duration `json:"-"`
+}
+
+// MarshalJSON adds the duration in milliseconds and the throughput to the JSON form
+func (s Summary) MarshalJSON() ([]byte, error) {
+	type plainSummary Summary
+	if s.Failures == nil {
+		s.Failures = []Failure{}
+	}
+	return json.Marshal(struct {
+		plainSummary
+		DurationMs     float64 `json:"durationMs"`
+		FilesPerSecond float64 `json:"filesPerSecond"`
+		BytesPerSecond float64 `json:"bytesPerSecond"`
+	}{
+		plainSummary:   plainSummary(s),
+		DurationMs:     float64(s.Duration) / float64(time.Millisecond),
+		FilesPerSecond: s.FilesPerSecond(),
+		BytesPerSecond: s.BytesPerSecond(),
+	})
+}
+
+// CategoryStats holds the files and bytes moved into a category
+type CategoryStats struct {
+	Files int   `json:"files"`
+	Bytes int64 `json:"bytes"`
+}
+
+// Failure describes a single file or folder that could not be processed
+type Failure struct {
+	Path      string `json:"path"`
+	Operation string `json:"operation"`
+	Reason    string `json:"reason"`
+}
+
+// NewSummary creates an empty summary ready to record a run
+func NewSummary() *Summary {
+	return &Summary{
+		Categories: make(map[string]*CategoryStats),
+		Extensions: make(map[string]int),
+	}
+}
+
+// RecordScanned counts a scanned file by its extension
+func (s *Summary) RecordScanned(path string) {
+	if s.Extensions == nil {
+		s.Extensions = make(map[string]int)
+	}
+	s.FilesScanned++
+	s.Extensions[strings.ToLower(filepath.Ext(path))]++
+}
+
+// RecordMove counts a file moved (or, in a dry run, to be moved) into a category
+func (s *Summary) RecordMove(category string, size int64) {
+	if s.Categories == nil {
+		s.Categories = make(map[string]*CategoryStats)
+	}
+	stats, exists := s.Categories[category]
+	if !exists {
+		stats = &CategoryStats{}
+		s.Categories[category] = stats
+	}
+	stats.Files++
+	stats.Bytes += size
+	s.FilesMoved++
+	s.BytesMoved += size
+}
+
+// RecordFailure records a file or folder that could not be processed
+func (s *Summary) RecordFailure(operation, path string, err error) {
+	s.Failures = append(s.Failures, Failure{Path: path, Operation: operation, Reason: err.Error()})
+}
+
+// CategoryNames returns the names of the categories files were moved into, sorted
+func (s *Summary) CategoryNames() []string {
+	names := make([]string, 0, len(s.Categories))
+	for name := range s.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TopExtensions returns the scanned extensions ordered by file count, then name
+func (s *Summary) TopExtensions() []string {
+	extensions := make([]string, 0, len(s.Extensions))
+	for ext := range s.Extensions {
+		extensions = append(extensions, ext)
+	}
+	sort.Slice(extensions, func(i, j int) bool {
+		if s.Extensions[extensions[i]] != s.Extensions[extensions[j]] {
+			return s.Extensions[extensions[i]] > s.Extensions[extensions[j]]
+		}
+		return extensions[i] < extensions[j]
+	})
+	return extensions
+}
+
+// FilesPerSecond returns the number of files processed per second
+func (s *Summary) FilesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.FilesScanned) / s.Duration.Seconds()
+}
+
+// BytesPerSecond returns the number of bytes moved per second
+func (s *Summary) BytesPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.BytesMoved) / s.Duration.Seconds()
+}
+
+// FormatBytes formats a byte count with a binary unit, e.g. "1.5 MiB"
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummaryRecording(t *testing.T) {
+	summary := NewSummary()
+
+	summary.RecordScanned("/tmp/a.PDF")
+	summary.RecordScanned("/tmp/b.pdf")
+	summary.RecordScanned("/tmp/c.go")
+	summary.RecordScanned("/tmp/README")
+
+	summary.RecordMove("Documents", 100)
+	summary.RecordMove("Documents", 50)
+	summary.RecordMove("Code", 10)
+	summary.RecordFailure("Move", "/tmp/README", errors.New("permission denied"))
+
+	assert.Equal(t, 4, summary.FilesScanned)
+	assert.Equal(t, 3, summary.FilesMoved)
+	assert.Equal(t, int64(160), summary.BytesMoved)
+	assert.Equal(t, []string{"Code", "Documents"}, summary.CategoryNames())
+	assert.Equal(t, CategoryStats{Files: 2, Bytes: 150}, *summary.Categories["Documents"])
+	assert.Equal(t, []string{".pdf", "", ".go"}, summary.TopExtensions())
+	assert.Equal(t, []Failure{{Path: "/tmp/README", Operation: "Move", Reason: "permission denied"}}, summary.Failures)
+
+	// A zero value summary records without panicking
+	var empty Summary
+	empty.RecordScanned("x.txt")
+	empty.RecordMove("Documents", 1)
+	assert.Equal(t, 1, empty.Extensions[".txt"])
+	assert.Equal(t, 1, empty.Categories["Documents"].Files)
+}
+
+func TestSummaryThroughput(t *testing.T) {
+	summary := &Summary{FilesScanned: 10, BytesMoved: 2048, Duration: 2 * time.Second}
+	assert.Equal(t, 5.0, summary.FilesPerSecond())
+	assert.Equal(t, 1024.0, summary.BytesPerSecond())
+
+	// No division by zero for instant runs
+	assert.Equal(t, 0.0, (&Summary{FilesScanned: 3}).FilesPerSecond())
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "0 B", FormatBytes(0))
+	assert.Equal(t, "1023 B", FormatBytes(1023))
+	assert.Equal(t, "1.0 KiB", FormatBytes(1024))
+	assert.Equal(t, "1.5 MiB", FormatBytes(1536*1024))
+	assert.Equal(t, "2.0 GiB", FormatBytes(2<<30))
+}
+
+func TestSummaryJSON(t *testing.T) {
+	summary := NewSummary()
+	summary.RecordScanned("a.pdf")
+	summary.RecordMove("Documents", 2048)
+	summary.Duration = 500 * time.Millisecond
+
+	data, err := json.Marshal(summary)
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 1.0, decoded["filesMoved"])
+	assert.Equal(t, 500.0, decoded["durationMs"])
+	assert.Equal(t, 4096.0, decoded["bytesPerSecond"])
+	assert.Equal(t, []interface{}{}, decoded["failures"])
+	assert.Equal(t, map[string]interface{}{"files": 1.0, "bytes": 2048.0}, decoded["categories"].(map[string]interface{})["Documents"])
+}
@@ -0,0 +1,853 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// knownConfigKeys lists the top-level keys accepted in a configuration file
+var knownConfigKeys = map[string]bool{
+	"customMappings": true,
+	"description":    true,
+	"profiles":       true,
+	"path":           true,
+	"dryRun":         true,
+	"progress":       true,
+	"watch":          true,
+	"profile":        true,
+	"ignoreFile":     true,
+	"logFile":        true,
+	"logLevel":       true,
+	"logFormat":      true,
+	"noLog":          true,
+	"logMaxSize":     true,
+	"logMaxBackups":  true,
+	"logMaxAge":      true,
+	"logCompress":    true,
+	"output":         true,
+	"unknownPolicy":  true,
+	"unknownFolder":  true,
+	"sniffContent":   true,
+	"skipCategories": true,
+}
+
+// knownProfileKeys lists the keys accepted inside a profile
+var knownProfileKeys = map[string]bool{
+	"extends":        true,
+	"customMappings": true,
+	"rules":          true,
+	"ignorePatterns": true,
+	"destination":    true,
+	"conflictPolicy": true,
+}
+
+// ConfigIssue describes a single problem found while validating configuration
+type ConfigIssue struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String formats the issue as file:line:column: message
+func (ci ConfigIssue) String() string {
+	switch {
+	case ci.Line > 0 && ci.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", ci.File, ci.Line, ci.Column, ci.Message)
+	case ci.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", ci.File, ci.Line, ci.Message)
+	default:
+		return fmt.Sprintf("%s: %s", ci.File, ci.Message)
+	}
+}
+
+// configNode is a parsed configuration value annotated with its source position
+type configNode struct {
+	line     int
+	column   int
+	isObject bool
+	isArray  bool
+	isString bool
+	isBool   bool
+	isInt    bool
+	value    string
+	fields   []configField
+	items    []*configNode
+}
+
+// configField is a key/value pair of an object node, in file order
+type configField struct {
+	key    string
+	line   int
+	column int
+	value  *configNode
+}
+
+// definedMapping records where an extension mapping was defined during validation
+type definedMapping struct {
+	category string
+	source   string
+	line     int
+	column   int
+}
+
+// ValidateConfigFiles strictly validates configuration files, the GFO_MAP
+// environment mappings and CLI mappings. Sources are applied in order on top of
+// the default mappings, so later config files, then environment and CLI
+// mappings take precedence over earlier ones, exactly as when organizing. All
+// problems are collected and returned at once, sorted by file and position. An
+// empty result means the configuration is valid.
+func ValidateConfigFiles(defaultMappings map[string]string, configPaths []string, envMappings string, cliMappings []string) []ConfigIssue {
+	var issues []ConfigIssue
+	em := NewExtensionMapping(defaultMappings)
+	effective := make(map[string]definedMapping)
+
+	// record applies a mapping and reports the one it shadows, if any
+	record := func(ext string, entry definedMapping) {
+		if previous, exists := effective[ext]; exists && previous.source != "default" && previous.source != entry.source && previous.category != entry.category {
+			issues = append(issues, ConfigIssue{
+				File:    previous.source,
+				Line:    previous.line,
+				Column:  previous.column,
+				Message: fmt.Sprintf("mapping %s=%s is shadowed by %s=%s from %s", ext, previous.category, ext, entry.category, describeEntry(entry)),
+			})
+		}
+		effective[ext] = entry
+	}
+
+	for ext, category := range defaultMappings {
+		effective[strings.ToLower(ext)] = definedMapping{category: category, source: "default"}
+	}
+
+	for _, configPath := range configPaths {
+		root, issue := parseConfigNodes(configPath)
+		if issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		issues = append(issues, validateConfigNode(em, configPath, root, record)...)
+	}
+
+	// override checks a .ext=Category mapping and applies it
+	override := func(source, mapping string) {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 {
+			issues = append(issues, ConfigIssue{File: source, Message: fmt.Sprintf("invalid mapping format '%s', expected '.ext=Category'", mapping)})
+			return
+		}
+
+		ext := strings.TrimSpace(parts[0])
+		category := strings.TrimSpace(parts[1])
+		if err := em.validateExtension(ext); err != nil {
+			issues = append(issues, ConfigIssue{File: source, Message: fmt.Sprintf("invalid extension '%s': %v", ext, err)})
+			return
+		}
+		if err := em.validateCategory(category); err != nil {
+			issues = append(issues, ConfigIssue{File: source, Message: fmt.Sprintf("invalid category '%s': %v", category, err)})
+			return
+		}
+		record(strings.ToLower(ext), definedMapping{category: category, source: source})
+	}
+
+	for _, mapping := range strings.FieldsFunc(envMappings, func(r rune) bool { return r == ',' || r == ';' }) {
+		if mapping = strings.TrimSpace(mapping); mapping != "" {
+			override(EnvName("map"), mapping)
+		}
+	}
+	for _, mapping := range cliMappings {
+		override("--map", mapping)
+	}
+
+	issues = append(issues, findCategoryCollisions(effective)...)
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+
+	return issues
+}
+
+// describeEntry returns a human readable location for a mapping entry
+func describeEntry(entry definedMapping) string {
+	if entry.line > 0 {
+		return fmt.Sprintf("%s:%d:%d", entry.source, entry.line, entry.column)
+	}
+	return entry.source
+}
+
+// validateConfigNode checks the structure and values of a parsed configuration file
+func validateConfigNode(em *ExtensionMapping, configPath string, root *configNode, record func(string, definedMapping)) []ConfigIssue {
+	var issues []ConfigIssue
+	issueAt := func(line, column int, format string, args ...interface{}) {
+		issues = append(issues, ConfigIssue{File: configPath, Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if root == nil {
+		return nil
+	}
+	if !root.isObject {
+		issueAt(root.line, root.column, "configuration must be an object")
+		return issues
+	}
+
+	for _, field := range root.fields {
+		if !knownConfigKeys[field.key] {
+			issueAt(field.line, field.column, "unknown key %q", field.key)
+			continue
+		}
+
+		switch field.key {
+		case "description", "path", "profile", "ignoreFile", "logFile", "output":
+			if !field.value.isString {
+				issueAt(field.value.line, field.value.column, "%s must be a string", field.key)
+			}
+		case "logLevel":
+			if !field.value.isString {
+				issueAt(field.value.line, field.value.column, "logLevel must be a string")
+			} else if _, err := ParseLogLevel(field.value.value); err != nil {
+				issueAt(field.value.line, field.value.column, "%v", err)
+			}
+		case "logFormat":
+			if !field.value.isString {
+				issueAt(field.value.line, field.value.column, "logFormat must be a string")
+			} else if _, err := ParseLogFormat(field.value.value); err != nil {
+				issueAt(field.value.line, field.value.column, "%v", err)
+			}
+		case "unknownPolicy":
+			if !field.value.isString {
+				issueAt(field.value.line, field.value.column, "unknownPolicy must be a string")
+			} else if _, err := ParseUnknownPolicy(field.value.value); err != nil {
+				issueAt(field.value.line, field.value.column, "%v", err)
+			}
+		case "unknownFolder":
+			if !field.value.isString {
+				issueAt(field.value.line, field.value.column, "unknownFolder must be a string")
+			} else if err := ValidateCategory(field.value.value); err != nil {
+				issueAt(field.value.line, field.value.column, "invalid unknownFolder '%s': %v", field.value.value, err)
+			}
+		case "skipCategories":
+			if !field.value.isArray {
+				issueAt(field.value.line, field.value.column, "skipCategories must be a list of categories")
+				continue
+			}
+			for _, item := range field.value.items {
+				if !item.isString {
+					issueAt(item.line, item.column, "skipCategories entries must be strings")
+				} else if err := em.validateCategory(item.value); err != nil {
+					issueAt(item.line, item.column, "invalid category '%s' in skipCategories: %v", item.value, err)
+				}
+			}
+		case "logMaxSize", "logMaxBackups", "logMaxAge":
+			if !field.value.isInt || strings.HasPrefix(field.value.value, "-") {
+				issueAt(field.value.line, field.value.column, "%s must be a non-negative integer", field.key)
+			}
+		case "dryRun", "progress", "watch", "sniffContent", "noLog", "logCompress":
+			if !field.value.isBool {
+				issueAt(field.value.line, field.value.column, "%s must be a boolean", field.key)
+			}
+		case "customMappings":
+			issues = append(issues, validateMappingsNode(em, configPath, field.value, record)...)
+		case "profiles":
+			issues = append(issues, validateProfilesNode(em, configPath, field.value)...)
+		}
+	}
+
+	return issues
+}
+
+// validateProfilesNode checks the profiles object, including inheritance between profiles
+func validateProfilesNode(em *ExtensionMapping, configPath string, node *configNode) []ConfigIssue {
+	var issues []ConfigIssue
+	issueAt := func(line, column int, format string, args ...interface{}) {
+		issues = append(issues, ConfigIssue{File: configPath, Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !node.isObject {
+		issueAt(node.line, node.column, "profiles must be an object of profile name to profile")
+		return issues
+	}
+
+	// Profile mappings are expected to override the top-level ones, so they are not checked for shadowing
+	ignoreShadowing := func(string, definedMapping) {}
+
+	extends := make(map[string]configField)
+	defined := make(map[string]bool)
+	for _, profile := range node.fields {
+		defined[profile.key] = true
+	}
+
+	for _, profile := range node.fields {
+		if !profile.value.isObject {
+			issueAt(profile.value.line, profile.value.column, "profile '%s' must be an object", profile.key)
+			continue
+		}
+
+		for _, field := range profile.value.fields {
+			if !knownProfileKeys[field.key] {
+				issueAt(field.line, field.column, "unknown key %q in profile '%s'", field.key, profile.key)
+				continue
+			}
+
+			value := field.value
+			switch field.key {
+			case "extends":
+				if !value.isString {
+					issueAt(value.line, value.column, "extends in profile '%s' must be a string", profile.key)
+				} else if !defined[value.value] {
+					issueAt(value.line, value.column, "profile '%s' extends undefined profile '%s'", profile.key, value.value)
+				} else {
+					extends[profile.key] = field
+				}
+			case "customMappings":
+				issues = append(issues, validateMappingsNode(em, configPath, value, ignoreShadowing)...)
+			case "rules":
+				issues = append(issues, validateRulesNode(em, configPath, profile.key, value)...)
+			case "ignorePatterns":
+				if !value.isArray {
+					issueAt(value.line, value.column, "ignorePatterns in profile '%s' must be a list of strings", profile.key)
+					continue
+				}
+				for _, item := range value.items {
+					if !item.isString {
+						issueAt(item.line, item.column, "ignore pattern in profile '%s' must be a string", profile.key)
+					}
+				}
+			case "destination":
+				if !value.isString || value.value == "" {
+					issueAt(value.line, value.column, "destination in profile '%s' must be a non-empty string", profile.key)
+				}
+			case "conflictPolicy":
+				if !value.isString {
+					issueAt(value.line, value.column, "conflictPolicy in profile '%s' must be a string", profile.key)
+				} else if _, err := ParseConflictPolicy(value.value); err != nil {
+					issueAt(value.line, value.column, "%v", err)
+				}
+			}
+		}
+	}
+
+	// Report each inheritance cycle once, at the profile that closes it
+	reported := make(map[string]bool)
+	for _, profile := range node.fields {
+		visited := map[string]bool{profile.key: true}
+		for current := profile.key; ; {
+			field, exists := extends[current]
+			if !exists {
+				break
+			}
+			next := field.value.value
+			if visited[next] {
+				if !reported[current] {
+					reported[current] = true
+					issueAt(field.value.line, field.value.column, "profile '%s' has an inheritance cycle through '%s'", current, next)
+				}
+				break
+			}
+			visited[next] = true
+			current = next
+		}
+	}
+
+	return issues
+}
+
+// validateRulesNode checks a profile's list of filename rules
+func validateRulesNode(em *ExtensionMapping, configPath, profileName string, node *configNode) []ConfigIssue {
+	var issues []ConfigIssue
+	issueAt := func(line, column int, format string, args ...interface{}) {
+		issues = append(issues, ConfigIssue{File: configPath, Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !node.isArray {
+		issueAt(node.line, node.column, "rules in profile '%s' must be a list", profileName)
+		return issues
+	}
+
+	for _, item := range node.items {
+		if !item.isObject {
+			issueAt(item.line, item.column, "rule in profile '%s' must be an object with pattern and category", profileName)
+			continue
+		}
+
+		var pattern, category *configNode
+		for _, field := range item.fields {
+			switch field.key {
+			case "pattern":
+				pattern = field.value
+			case "category":
+				category = field.value
+			default:
+				issueAt(field.line, field.column, "unknown key %q in rule", field.key)
+			}
+		}
+
+		if pattern == nil || !pattern.isString {
+			issueAt(item.line, item.column, "rule in profile '%s' needs a string pattern", profileName)
+		} else if err := validateRulePattern(pattern.value); err != nil {
+			issueAt(pattern.line, pattern.column, "%v", err)
+		}
+
+		if category == nil || !category.isString {
+			issueAt(item.line, item.column, "rule in profile '%s' needs a string category", profileName)
+		} else if err := em.validateCategory(category.value); err != nil {
+			issueAt(category.line, category.column, "invalid category '%s' for rule: %v", category.value, err)
+		}
+	}
+
+	return issues
+}
+
+// validateMappingsNode checks a customMappings object and records each valid mapping
+func validateMappingsNode(em *ExtensionMapping, configPath string, node *configNode, record func(string, definedMapping)) []ConfigIssue {
+	var issues []ConfigIssue
+	issueAt := func(line, column int, format string, args ...interface{}) {
+		issues = append(issues, ConfigIssue{File: configPath, Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !node.isObject {
+		issueAt(node.line, node.column, "customMappings must be an object of extension to category")
+		return issues
+	}
+
+	seen := make(map[string]configField)
+	for _, field := range node.fields {
+		if err := em.validateExtension(field.key); err != nil {
+			issueAt(field.line, field.column, "invalid extension '%s': %v", field.key, err)
+			continue
+		}
+		if !field.value.isString {
+			issueAt(field.value.line, field.value.column, "category for extension '%s' must be a string", field.key)
+			continue
+		}
+		if err := em.validateCategory(field.value.value); err != nil {
+			issueAt(field.value.line, field.value.column, "invalid category '%s' for extension '%s': %v", field.value.value, field.key, err)
+			continue
+		}
+
+		ext := strings.ToLower(field.key)
+		if first, exists := seen[ext]; exists {
+			issueAt(field.line, field.column, "duplicate extension '%s' (first defined at line %d)", field.key, first.line)
+		} else {
+			seen[ext] = field
+		}
+
+		record(ext, definedMapping{category: field.value.value, source: configPath, line: field.line, column: field.column})
+	}
+
+	return issues
+}
+
+// findCategoryCollisions reports categories that differ only by case, which
+// would end up in the same folder on case-insensitive filesystems
+func findCategoryCollisions(effective map[string]definedMapping) []ConfigIssue {
+	var issues []ConfigIssue
+
+	// Group the effective categories by their case-folded name
+	variants := make(map[string]map[string]bool)
+	for _, entry := range effective {
+		folded := strings.ToLower(entry.category)
+		if variants[folded] == nil {
+			variants[folded] = make(map[string]bool)
+		}
+		variants[folded][entry.category] = true
+	}
+
+	exts := make([]string, 0, len(effective))
+	for ext := range effective {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	for _, ext := range exts {
+		entry := effective[ext]
+		if entry.source == "default" || len(variants[strings.ToLower(entry.category)]) < 2 {
+			continue
+		}
+
+		var others []string
+		for name := range variants[strings.ToLower(entry.category)] {
+			if name != entry.category {
+				others = append(others, name)
+			}
+		}
+		sort.Strings(others)
+
+		issues = append(issues, ConfigIssue{
+			File:    entry.source,
+			Line:    entry.line,
+			Column:  entry.column,
+			Message: fmt.Sprintf("category '%s' for extension '%s' collides case-insensitively with '%s'", entry.category, ext, strings.Join(others, "', '")),
+		})
+	}
+
+	return issues
+}
+
+// parseConfigNodes reads a configuration file into a position-annotated tree.
+// A parse failure is returned as an issue pointing at the offending location.
+func parseConfigNodes(configPath string) (*configNode, *ConfigIssue) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, &ConfigIssue{File: configPath, Message: fmt.Sprintf("failed to read config file: %v", err)}
+	}
+
+	var root *configNode
+	var line, column int
+
+	format := DetectConfigFormat(configPath)
+	switch format {
+	case ConfigFormatYAML:
+		root, line, err = parseYAMLNodes(data)
+	case ConfigFormatTOML:
+		root, line, column, err = parseTOMLNodes(data)
+	default:
+		root, line, column, err = parseJSONNodes(data)
+	}
+
+	if err != nil {
+		return nil, &ConfigIssue{File: configPath, Line: line, Column: column, Message: fmt.Sprintf("failed to parse config %s: %v", format, err)}
+	}
+
+	return root, nil
+}
+
+// parseJSONNodes parses JSON data, preserving key order, duplicate keys and positions
+func parseJSONNodes(data []byte) (*configNode, int, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	root, err := decodeJSONNode(decoder, data)
+	if err == nil {
+		if _, extraErr := decoder.Token(); extraErr != io.EOF {
+			offset := int(decoder.InputOffset())
+			line, column := offsetToPosition(data, offset)
+			return nil, line, column, fmt.Errorf("unexpected data after top-level value")
+		}
+		return root, 0, 0, nil
+	}
+
+	offset := len(data)
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Offset > 0 {
+		// The offset points just past the offending byte
+		offset = int(syntaxErr.Offset) - 1
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	line, column := offsetToPosition(data, offset)
+	return nil, line, column, err
+}
+
+// decodeJSONNode decodes the next JSON value from the decoder as a node
+func decodeJSONNode(decoder *json.Decoder, data []byte) (*configNode, error) {
+	start := tokenStart(data, int(decoder.InputOffset()))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &configNode{}
+	node.line, node.column = offsetToPosition(data, start)
+
+	switch value := token.(type) {
+	case json.Delim:
+		switch value {
+		case '{':
+			node.isObject = true
+			for decoder.More() {
+				keyStart := tokenStart(data, int(decoder.InputOffset()))
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				field := configField{key: keyToken.(string)}
+				field.line, field.column = offsetToPosition(data, keyStart)
+				if field.value, err = decodeJSONNode(decoder, data); err != nil {
+					return nil, err
+				}
+				node.fields = append(node.fields, field)
+			}
+		case '[':
+			node.isArray = true
+			for decoder.More() {
+				item, err := decodeJSONNode(decoder, data)
+				if err != nil {
+					return nil, err
+				}
+				node.items = append(node.items, item)
+			}
+		}
+
+		// Consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.isString = true
+		node.value = value
+	case bool:
+		node.isBool = true
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			node.isInt = true
+			node.value = value.String()
+		}
+	}
+
+	return node, nil
+}
+
+// tokenStart skips whitespace and separators to find where the next JSON token begins
+func tokenStart(data []byte, offset int) int {
+	for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// offsetToPosition converts a byte offset into a 1-based line and column
+func offsetToPosition(data []byte, offset int) (int, int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, column
+}
+
+// yamlErrorLine extracts the line number from a yaml.v3 error message
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// parseYAMLNodes parses YAML data into a node tree using yaml.v3 node positions
+func parseYAMLNodes(data []byte) (*configNode, int, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		line := 0
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		return nil, line, err
+	}
+
+	// An empty document is an empty configuration
+	if len(document.Content) == 0 {
+		return &configNode{isObject: true, line: 1, column: 1}, 0, nil
+	}
+
+	return convertYAMLNode(document.Content[0]), 0, nil
+}
+
+// convertYAMLNode converts a yaml.v3 node into a configNode
+func convertYAMLNode(yamlNode *yaml.Node) *configNode {
+	node := &configNode{line: yamlNode.Line, column: yamlNode.Column}
+
+	switch yamlNode.Kind {
+	case yaml.MappingNode:
+		node.isObject = true
+		for i := 0; i+1 < len(yamlNode.Content); i += 2 {
+			key := yamlNode.Content[i]
+			node.fields = append(node.fields, configField{
+				key:    key.Value,
+				line:   key.Line,
+				column: key.Column,
+				value:  convertYAMLNode(yamlNode.Content[i+1]),
+			})
+		}
+	case yaml.SequenceNode:
+		node.isArray = true
+		for _, item := range yamlNode.Content {
+			node.items = append(node.items, convertYAMLNode(item))
+		}
+	case yaml.ScalarNode:
+		switch yamlNode.Tag {
+		case "!!str":
+			node.isString = true
+			node.value = yamlNode.Value
+		case "!!bool":
+			node.isBool = true
+		case "!!int":
+			node.isInt = true
+			node.value = yamlNode.Value
+		}
+	case yaml.AliasNode:
+		if yamlNode.Alias != nil {
+			converted := convertYAMLNode(yamlNode.Alias)
+			converted.line, converted.column = yamlNode.Line, yamlNode.Column
+			return converted
+		}
+	}
+
+	return node
+}
+
+// parseTOMLNodes parses TOML data into a node tree. The TOML decoder does not
+// expose key positions, so they are recovered by scanning the source lines.
+func parseTOMLNodes(data []byte) (*configNode, int, int, error) {
+	var raw map[string]interface{}
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		var parseErr toml.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.Position.Line, parseErr.Position.Col, errors.New(parseErr.Message)
+		}
+		return nil, 0, 0, err
+	}
+
+	positions := tomlKeyPositions(data)
+	root := convertTOMLValue(raw, nil, positions)
+	root.line, root.column = 1, 1
+	return root, 0, 0, nil
+}
+
+// convertTOMLValue converts a decoded TOML value into a configNode
+func convertTOMLValue(value interface{}, path []string, positions map[string][2]int) *configNode {
+	node := &configNode{}
+	if position, exists := positions[strings.Join(path, "\x00")]; exists {
+		node.line, node.column = position[0], position[1]
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		node.isObject = true
+		for key, child := range typed {
+			childPath := append(append([]string{}, path...), key)
+			field := configField{key: key, value: convertTOMLValue(child, childPath, positions)}
+			field.line, field.column = field.value.line, field.value.column
+			node.fields = append(node.fields, field)
+		}
+
+		// Keep fields in file order
+		sort.SliceStable(node.fields, func(i, j int) bool {
+			if node.fields[i].line != node.fields[j].line {
+				return node.fields[i].line < node.fields[j].line
+			}
+			return node.fields[i].key < node.fields[j].key
+		})
+	case []map[string]interface{}:
+		// Arrays of tables share the position of their header
+		node.isArray = true
+		for _, item := range typed {
+			child := convertTOMLValue(item, path, positions)
+			child.line, child.column = node.line, node.column
+			node.items = append(node.items, child)
+		}
+	case []interface{}:
+		node.isArray = true
+		for _, item := range typed {
+			child := convertTOMLValue(item, path, positions)
+			child.line, child.column = node.line, node.column
+			node.items = append(node.items, child)
+		}
+	case string:
+		node.isString = true
+		node.value = typed
+	case bool:
+		node.isBool = true
+	case int64:
+		node.isInt = true
+		node.value = strconv.FormatInt(typed, 10)
+	}
+
+	return node
+}
+
+// tomlKeyPositions maps key paths (joined with NUL) to their line and column
+func tomlKeyPositions(data []byte) map[string][2]int {
+	positions := make(map[string][2]int)
+	var table []string
+
+	for index, rawLine := range strings.Split(string(data), "\n") {
+		lineNumber := index + 1
+		trimmed := strings.TrimSpace(rawLine)
+		column := len(rawLine) - len(strings.TrimLeft(rawLine, " \t")) + 1
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// Table headers: [table] or [[array]]
+		if strings.HasPrefix(trimmed, "[") {
+			header := strings.Trim(strings.SplitN(trimmed, "#", 2)[0], " \t[]")
+			table = splitTOMLKey(header)
+			key := strings.Join(table, "\x00")
+			if _, exists := positions[key]; !exists {
+				positions[key] = [2]int{lineNumber, column}
+			}
+			continue
+		}
+
+		equals := tomlKeyEnd(trimmed)
+		if equals < 0 {
+			continue
+		}
+
+		path := append(append([]string{}, table...), splitTOMLKey(trimmed[:equals])...)
+		positions[strings.Join(path, "\x00")] = [2]int{lineNumber, column}
+	}
+
+	return positions
+}
+
+// tomlKeyEnd returns the index of the '=' separating a key from its value, ignoring quoted sections
+func tomlKeyEnd(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch {
+		case quote != 0 && line[i] == quote:
+			quote = 0
+		case quote == 0 && (line[i] == '"' || line[i] == '\''):
+			quote = line[i]
+		case quote == 0 && line[i] == '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// splitTOMLKey splits a possibly dotted and quoted TOML key into its parts
+func splitTOMLKey(key string) []string {
+	var parts []string
+	var current strings.Builder
+	var quote byte
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteByte(c)
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(current.String()))
+			current.Reset()
+		case c == ' ' || c == '\t':
+			// Whitespace around bare keys and dots is insignificant
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	return append(parts, current.String())
+}
@@ -0,0 +1,267 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// findIssue returns the first issue whose message contains the given text
+func findIssue(issues []ConfigIssue, text string) (ConfigIssue, bool) {
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, text) {
+			return issue, true
+		}
+	}
+	return ConfigIssue{}, false
+}
+
+func TestValidateConfigFilesValid(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "validate-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.json")
+	err = os.WriteFile(configPath, []byte(`{"customMappings": {".md": "Notes"}, "description": "ok"}`), 0644)
+	assert.NoError(t, err)
+
+	issues := ValidateConfigFiles(map[string]string{".txt": "Documents"}, []string{configPath}, "", []string{".log=Logs"})
+	assert.Empty(t, issues)
+}
+
+func TestValidateConfigFilesJSONProblems(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "validate-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.json")
+	configContent := `{
+  "customMappings": {
+    ".md": "Notes",
+    ".MD": "Docs",
+    "txt": "Text",
+    ".img": "images"
+  },
+  "extra": true
+}`
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	issues := ValidateConfigFiles(map[string]string{".jpg": "Images"}, []string{configPath}, "", nil)
+
+	issue, found := findIssue(issues, "duplicate extension '.MD'")
+	assert.True(t, found)
+	assert.Equal(t, configPath, issue.File)
+	assert.Equal(t, 4, issue.Line)
+	assert.Equal(t, 5, issue.Column)
+
+	issue, found = findIssue(issues, "invalid extension 'txt'")
+	assert.True(t, found)
+	assert.Equal(t, 5, issue.Line)
+
+	issue, found = findIssue(issues, "collides case-insensitively with 'Images'")
+	assert.True(t, found)
+	assert.Equal(t, 6, issue.Line)
+
+	issue, found = findIssue(issues, `unknown key "extra"`)
+	assert.True(t, found)
+	assert.Equal(t, 8, issue.Line)
+	assert.Equal(t, 3, issue.Column)
+	assert.Equal(t, configPath+":8:3: unknown key \"extra\"", issue.String())
+}
+
+func TestValidateConfigFilesParseErrorLocation(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "validate-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	jsonPath := filepath.Join(tempDir, "config.json")
+	err = os.WriteFile(jsonPath, []byte("{\n  \"customMappings\": {\n    \".md\": \n  }\n}"), 0644)
+	assert.NoError(t, err)
+
+	tomlPath := filepath.Join(tempDir, "config.toml")
+	err = os.WriteFile(tomlPath, []byte("[customMappings]\n\".md\" = \n"), 0644)
+	assert.NoError(t, err)
+
+	issues := ValidateConfigFiles(nil, []string{jsonPath, tomlPath}, "", nil)
+	assert.Len(t, issues, 2)
+
+	issue, found := findIssue(issues, "failed to parse config JSON")
+	assert.True(t, found)
+	assert.Equal(t, 4, issue.Line)
+	assert.Equal(t, 3, issue.Column)
+
+	issue, found = findIssue(issues, "failed to parse config TOML")
+	assert.True(t, found)
+	assert.Equal(t, 2, issue.Line)
+}
+
+func TestValidateConfigFilesShadowing(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "validate-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	basePath := filepath.Join(tempDir, "base.yaml")
+	err = os.WriteFile(basePath, []byte("customMappings:\n  .md: Notes\n  .log: Logs\n"), 0644)
+	assert.NoError(t, err)
+
+	overridePath := filepath.Join(tempDir, "override.toml")
+	err = os.WriteFile(overridePath, []byte("[customMappings]\n\".md\" = \"Docs\"\n"), 0644)
+	assert.NoError(t, err)
+
+	issues := ValidateConfigFiles(nil, []string{basePath, overridePath}, "", []string{".log=Journal"})
+	assert.Len(t, issues, 2)
+
+	issue, found := findIssue(issues, "mapping .md=Notes is shadowed by .md=Docs")
+	assert.True(t, found)
+	assert.Equal(t, basePath, issue.File)
+	assert.Equal(t, 2, issue.Line)
+	assert.Contains(t, issue.Message, overridePath+":2:1")
+
+	issue, found = findIssue(issues, "mapping .log=Logs is shadowed by .log=Journal from --map")
+	assert.True(t, found)
+	assert.Equal(t, 3, issue.Line)
+}
+
+func TestValidateConfigFilesEnvMappings(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(configPath, []byte("customMappings:\n  .md: Notes\n  .log: Logs\n"), 0644)
+	assert.NoError(t, err)
+
+	issues := ValidateConfigFiles(nil, []string{configPath}, ".md=Docs; .log=Journal", []string{".log=Archive"})
+	assert.Len(t, issues, 3)
+
+	issue, found := findIssue(issues, "mapping .md=Notes is shadowed by .md=Docs from GFO_MAP")
+	assert.True(t, found)
+	assert.Equal(t, 2, issue.Line)
+
+	issue, found = findIssue(issues, "mapping .log=Logs is shadowed by .log=Journal from GFO_MAP")
+	assert.True(t, found)
+	assert.Equal(t, 3, issue.Line)
+
+	// --map takes precedence over GFO_MAP
+	issue, found = findIssue(issues, "mapping .log=Journal is shadowed by .log=Archive from --map")
+	assert.True(t, found)
+	assert.Equal(t, "GFO_MAP", issue.File)
+
+	issues = ValidateConfigFiles(nil, nil, ".md", nil)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "GFO_MAP", issues[0].File)
+	}
+}
+
+func TestValidateConfigFilesYAMLTypes(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "validate-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.yml")
+	err = os.WriteFile(configPath, []byte("customMappings:\n  .x: 3\nfoo: bar\n"), 0644)
+	assert.NoError(t, err)
+
+	issues := ValidateConfigFiles(nil, []string{configPath}, "", []string{"bad"})
+
+	issue, found := findIssue(issues, "category for extension '.x' must be a string")
+	assert.True(t, found)
+	assert.Equal(t, 2, issue.Line)
+	assert.Equal(t, 7, issue.Column)
+
+	_, found = findIssue(issues, `unknown key "foo"`)
+	assert.True(t, found)
+
+	issue, found = findIssue(issues, "invalid mapping format 'bad'")
+	assert.True(t, found)
+	assert.Equal(t, "--map", issue.File)
+}
+
+func TestValidateConfigFilesUnknownPolicy(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := "unknownPolicy: shred\nunknownFolder: \"a/b\"\nsniffContent: yes please\nskipCategories:\n  - Code\n  - 7\n"
+	assert.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	issues := ValidateConfigFiles(nil, []string{configPath}, "", nil)
+	assert.Len(t, issues, 4)
+
+	issue, found := findIssue(issues, "unknown file policy 'shred'")
+	assert.True(t, found)
+	assert.Equal(t, 1, issue.Line)
+
+	_, found = findIssue(issues, "invalid unknownFolder 'a/b'")
+	assert.True(t, found)
+
+	_, found = findIssue(issues, "sniffContent must be a boolean")
+	assert.True(t, found)
+
+	issue, found = findIssue(issues, "skipCategories entries must be strings")
+	assert.True(t, found)
+	assert.Equal(t, 6, issue.Line)
+}
+
+func TestValidateConfigFilesLogSettings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	configPath := filepath.Join(tempDir, "config.yaml")
+	configContent := "logLevel: loud\nlogFormat: xml\nlogMaxSize: -1\nlogMaxBackups: many\nlogMaxAge: 7\nlogCompress: true\nnoLog: 1\n"
+	assert.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	issues := ValidateConfigFiles(nil, []string{configPath}, "", nil)
+	assert.Len(t, issues, 5)
+
+	issue, found := findIssue(issues, "unknown log level 'loud'")
+	assert.True(t, found)
+	assert.Equal(t, 1, issue.Line)
+
+	issue, found = findIssue(issues, "unknown log format 'xml'")
+	assert.True(t, found)
+	assert.Equal(t, 2, issue.Line)
+
+	issue, found = findIssue(issues, "logMaxSize must be a non-negative integer")
+	assert.True(t, found)
+	assert.Equal(t, 3, issue.Line)
+
+	_, found = findIssue(issues, "logMaxBackups must be a non-negative integer")
+	assert.True(t, found)
+
+	_, found = findIssue(issues, "noLog must be a boolean")
+	assert.True(t, found)
+}
+
+func TestValidateConfigFilesProfiles(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "validate-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tempDir)
+
+	configPath := filepath.Join(tempDir, "config.json")
+	configContent := `{
+  "profiles": {
+    "a": {"extends": "b", "conflictPolicy": "merge"},
+    "b": {"extends": "a", "colour": "blue"},
+    "c": {"extends": "missing", "rules": [{"pattern": "[", "category": "X"}]}
+  }
+}`
+	err = os.WriteFile(configPath, []byte(configContent), 0644)
+	assert.NoError(t, err)
+
+	issues := ValidateConfigFiles(nil, []string{configPath}, "", nil)
+
+	_, found := findIssue(issues, "unknown conflict policy 'merge'")
+	assert.True(t, found)
+
+	issue, found := findIssue(issues, `unknown key "colour" in profile 'b'`)
+	assert.True(t, found)
+	assert.Equal(t, 4, issue.Line)
+
+	_, found = findIssue(issues, "inheritance cycle")
+	assert.True(t, found)
+
+	_, found = findIssue(issues, "extends undefined profile 'missing'")
+	assert.True(t, found)
+
+	_, found = findIssue(issues, "invalid rule pattern '['")
+	assert.True(t, found)
+}
@@ -1,15 +1,17 @@
 // Entry point of the go-file-organizer CLI tool.
 // This tool organizes files in a given directory by file type.
-// Supports flags like --path, --dry-run, --map, and --help.
 
-// 1. Use the flag package to parse command-line arguments:
+// 1. Dispatch the subcommands: organize, watch, plan, apply, undo, stats,
+//    config, init, check-ignore, completion and version, each with its own
+//    flag set, which also drives shell completion.
+
+// 2. Without a subcommand, keep the original flags working:
 //    --path string: the target directory
 //    --dry-run bool: if true, show what would be done without moving files
+//    --watch bool: keep organizing new files after the first run
 //    --map .ext=Category: override extension mappings (can be used multiple times)
 
-// 2. Print usage instructions when no path is provided.
-
-// 3. Load configuration from config.json and .organizerignore files.
+// 3. Load configuration from config.json (or .yaml/.toml) and .organizerignore files.
 
 // 4. Call the internal organizer logic with custom configuration.
 
@@ -22,6 +24,7 @@ import (
 	"go-file-organizer/internal/utils"
 	"go-file-organizer/internal/version"
 	"os"
+	"strings"
 )
 
 // arrayFlags allows multiple values for the same flag
@@ -37,106 +40,161 @@ func (af *arrayFlags) Set(value string) error {
 }
 
 func main() {
-	// Define flags
-	path := flag.String("path", "", "Path to the folder to organize")
-	dryRun := flag.Bool("dry-run", false, "Preview actions without moving files")
-	versionFlag := flag.Bool("version", false, "Show version information")
-	progress := flag.Bool("progress", false, "Show progress bar during organization")
-	watch := flag.Bool("watch", false, "Watch directory for new files and organize them automatically")
-	help := flag.Bool("help", false, "Show usage")
-
-	// Define flag for multiple mapping overrides
-	var mapOverrides arrayFlags
-	flag.Var(&mapOverrides, "map", "Override extension mappings (format: .ext=Category, can be used multiple times)")
-
-	flag.Parse()
-
-	if *versionFlag {
-		fmt.Println(version.GetVersionInfo())
-		os.Exit(0)
+	// Flags without a subcommand are the original organize command line
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runRootCommand(os.Args[1:]))
 	}
 
-	if *help || *path == "" {
-		fmt.Println("Usage: go-file-organizer --path <directory> [--dry-run] [--progress] [--watch] [--map .ext=Category]")
-		flag.PrintDefaults()
-		os.Exit(0)
+	code, found := runCommand(os.Args[1], os.Args[2:])
+	if !found {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
+		printUsage()
+		os.Exit(exitUsage)
 	}
+	os.Exit(code)
+}
 
-	fmt.Println("Organizing path:", *path)
-	fmt.Println("Dry run mode:", *dryRun)
-
-	// Initialize configuration
-	extensionMapping := utils.NewExtensionMapping(organizer.GetDefaultExtensionCategories())
+// command is a subcommand of the CLI. Its flag set is used both to parse its
+// arguments and to complete them in the shell.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+	// flags defines the command's flags; nil if it has none
+	flags func() *flag.FlagSet
+	// subcommands are the commands of a command group such as config
+	subcommands []command
+	// hidden commands are left out of the usage and completion
+	hidden bool
+}
 
-	// Load config file if it exists
-	configPath := "config/config.json"
-	if err := extensionMapping.LoadConfig(configPath); err != nil {
-		fmt.Printf("Warning: Could not load config file: %v\n", err)
-		fmt.Println("Continuing with default mappings...")
+// commands lists the subcommands in the order the usage shows them
+func commands() []command {
+	return []command{
+		{name: "organize", summary: "Organize the files of a directory into category folders", run: runOrganizeCommand, flags: organizeFlags},
+		{name: "watch", summary: "Organize a directory, then keep organizing new files", run: runWatchCommand, flags: watchFlags},
+		{name: "plan", summary: "Save the moves organize would make to a plan file", run: runPlanCommand, flags: planFlags},
+		{name: "apply", summary: "Execute a plan file", run: runApplyCommand, flags: applyFlags},
+		{name: "undo", summary: "Move the files of a previous run back", run: runUndoCommand, flags: undoFlags},
+		{name: "stats", summary: "Show the files of a directory per category and extension", run: runStatsCommand, flags: statsFlags},
+		{name: "config", summary: "Validate or show configuration files", run: runConfigCommand, subcommands: []command{
+			{name: "validate", summary: "Strictly validate config files", flags: configValidateFlags},
+			{name: "show", summary: "Show the effective mappings and where they came from", flags: configShowFlags},
+		}},
+		{name: "init", summary: "Write a starter config and ignore file for a directory", run: runInitCommand, flags: initFlags},
+		{name: "check-ignore", summary: "Show which ignore pattern excludes a path", run: runCheckIgnoreCommand, flags: checkIgnoreFlags},
+		{name: "completion", summary: "Print the shell completion script for bash, zsh or fish", run: runCompletionCommand, flags: completionFlags},
+		{name: "version", summary: "Show version information", run: runVersionCommand},
+		{name: "help", summary: "Show the usage of the tool or of a command", run: runHelpCommand},
+		{name: completeCommand, run: runCompleteCommand, hidden: true},
 	}
+}
 
-	// Apply CLI mapping overrides
-	if len(mapOverrides) > 0 {
-		if err := extensionMapping.ApplyCLIMappings(mapOverrides); err != nil {
-			fmt.Printf("Error applying CLI mappings: %v\n", err)
-			os.Exit(1)
+// findCommand returns the command with the given name
+func findCommand(list []command, name string) (command, bool) {
+	for _, cmd := range list {
+		if cmd.name == name {
+			return cmd, true
 		}
 	}
+	return command{}, false
+}
 
-	// Initialize ignore manager
-	ignoreManager := utils.NewIgnoreManager(*path)
-	ignoreFilePath := ".organizerignore"
-	if err := ignoreManager.LoadIgnoreFile(ignoreFilePath); err != nil {
-		fmt.Printf("Warning: Could not load ignore file: %v\n", err)
-		fmt.Println("Continuing without ignore rules...")
+// runCommand runs a subcommand and returns its exit code, or false if there
+// is no command with that name
+func runCommand(name string, args []string) (int, bool) {
+	cmd, found := findCommand(commands(), name)
+	if !found {
+		return 0, false
 	}
+	return cmd.run(args), true
+}
 
-	// Print summary of custom rules
-	if len(mapOverrides) > 0 {
-		extensionMapping.PrintSummary()
-		ignoreManager.PrintSummary()
-	}
+// runVersionCommand prints the version information
+func runVersionCommand(args []string) int {
+	fmt.Println(version.GetVersionInfo())
+	return exitOK
+}
 
-	// Initialize logger
-	logger, err := utils.NewLogger("organizer.log")
-	if err != nil {
-		fmt.Printf("Warning: Could not create log file: %v\n", err)
-		fmt.Println("Continuing without logging...")
+// runHelpCommand prints the usage of the tool or of one command
+func runHelpCommand(args []string) int {
+	if len(args) == 0 || args[0] == "help" {
+		printUsage()
+		return exitOK
 	}
-	defer func() {
-		if logger != nil {
-			logger.Close()
-		}
-	}()
-
-	// Organize files
-	if *dryRun {
-		fmt.Println("\n🔮 DRY-RUN MODE: Simulating file organization...")
-	} else {
-		fmt.Println("\n🚀 ORGANIZING FILES...")
+	if args[0] == "version" {
+		fmt.Println("Usage: go-file-organizer version")
+		return exitOK
+	}
+	code, found := runCommand(args[0], []string{"--help"})
+	if !found {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", args[0])
+		printUsage()
+		return exitUsage
 	}
+	return code
+}
 
-	summary, err := organizer.OrganizeFilesWithConfig(*path, *dryRun, logger, extensionMapping, ignoreManager, *progress)
-	if err != nil {
-		fmt.Printf("Error organizing files: %v\n", err)
-		os.Exit(1)
+// printUsage prints usage instructions for the tool and lists its commands
+func printUsage() {
+	fmt.Println("Usage: go-file-organizer <command> [options]")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, cmd := range commands() {
+		if !cmd.hidden {
+			fmt.Printf("  %-14s%s\n", cmd.name, cmd.summary)
+		}
 	}
+	fmt.Println()
+	fmt.Println("Run 'go-file-organizer help <command>' or 'go-file-organizer <command> --help' for its options.")
+	fmt.Println()
+	fmt.Println("Without a command the organize options are accepted directly, as in earlier versions:")
+	fmt.Println("  go-file-organizer --path <directory> [--dry-run [--script file]] [--progress] [--watch] [--version]")
+	fmt.Printf("\nEvery option except --help and --version can also be set with a %s* environment variable,\n", utils.EnvPrefix)
+	fmt.Printf("e.g. %s=~/Downloads or %s=true. Precedence: default < config file < environment < CLI.\n", utils.EnvName("path"), utils.EnvName("dry-run"))
+}
 
-	// Print summary
-	organizer.PrintSummary(summary, *dryRun)
+// Exit codes of the CLI. Scripts and schedulers rely on them, so they are
+// documented in the README and never renumbered.
+const (
+	// exitOK means the command did everything it was asked to
+	exitOK = 0
+	// exitError means the command failed, e.g. a plan could not be written
+	exitError = 1
+	// exitUsage means the command line is invalid
+	exitUsage = 2
+	// exitPartialFailure means the run finished, or stopped with
+	// --fail-on-error, but some files could not be moved
+	exitPartialFailure = 3
+	// exitConfigError means the config file, a profile, a mapping or a setting
+	// is invalid
+	exitConfigError = 4
+	// exitPathError means the directory to organize is missing or not a directory
+	exitPathError = 5
+	// exitInterrupted means the run was stopped by Ctrl+C or SIGTERM (128 + SIGINT),
+	// except in watch mode, which they end as usual
+	exitInterrupted = 130
+)
 
-	if logger != nil {
-		fmt.Printf("\n📝 Detailed log written to: organizer.log\n")
-	}
+// exitWithError reports a fatal error and exits with the given code
+func exitWithError(events *eventOutput, code int, format string, args ...interface{}) {
+	os.Exit(reportErrorCode(events, code, format, args...))
+}
 
-	// Start watch mode if requested
-	if *watch {
-		fmt.Printf("\n👀 Starting watch mode for directory: %s\n", *path)
-		fmt.Println("Press Ctrl+C to stop watching...")
-		
-		if err := organizer.StartWatchMode(*path, *dryRun, logger, extensionMapping, ignoreManager, *progress); err != nil {
-			fmt.Printf("Error starting watch mode: %v\n", err)
-			os.Exit(1)
-		}
+// reportError reports a fatal error, as an error event when structured output
+// is enabled, and returns exitError
+func reportError(events *eventOutput, format string, args ...interface{}) int {
+	return reportErrorCode(events, exitError, format, args...)
+}
+
+// reportErrorCode reports a fatal error like reportError and returns the given
+// exit code
+func reportErrorCode(events *eventOutput, code int, format string, args ...interface{}) int {
+	message := fmt.Sprintf(format, args...)
+	fmt.Fprintf(events.Text(), "Error: %s\n", message)
+	if events.machine() {
+		events.Emit(organizer.Event{Type: organizer.EventError, Reason: message})
+		events.Finish()
 	}
+	return code
 }
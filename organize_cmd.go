@@ -0,0 +1,252 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/tui"
+	"go-file-organizer/internal/version"
+	"os"
+)
+
+// defineOrganizeFlags defines the flags shared by the organize, watch and root commands
+func defineOrganizeFlags(flags *flag.FlagSet) {
+	defineSelectionFlags(flags)
+	defineLogFlags(flags)
+	defineJournalFlag(flags)
+	flags.Bool("dry-run", false, "Preview actions without moving files")
+	flags.Bool("progress", false, "Show progress bar during organization")
+	flags.Bool("fail-on-error", false, "Stop at the first file that cannot be moved instead of carrying on")
+}
+
+// organizeFlags defines the flags of the organize command
+func organizeFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("organize", flag.ContinueOnError)
+	defineOrganizeFlags(flags)
+	flags.String("script", "", "With --dry-run, also write the moves as a POSIX shell script to this file")
+	flags.Bool("interactive", false, "Review the planned moves file by file before anything is moved")
+	flags.Bool("tui", false, "Browse and adjust the planned moves in a full-screen terminal UI before anything is moved")
+	flags.Bool("help", false, "Show usage")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer organize --path <directory> [--dry-run [--script file]] [--interactive | --tui] [--progress] [--fail-on-error] [--files-from file [-0]] [--profile name] [--map .ext=Category] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runOrganizeCommand organizes the files of a directory once
+func runOrganizeCommand(args []string) int {
+	flags := organizeFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	run := prepareRun(flags, mappingOverrides(flags), boolFlag(flags, "help"), flags.Usage)
+	defer run.close()
+	run.watch = false
+	return organizeDirectory(run)
+}
+
+// watchFlags defines the flags of the watch command
+func watchFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	defineOrganizeFlags(flags)
+	flags.Bool("help", false, "Show usage")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer watch --path <directory> [--dry-run] [--progress] [--fail-on-error] [--profile name] [--map .ext=Category] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runWatchCommand organizes the files of a directory, then keeps organizing
+// new files until interrupted
+func runWatchCommand(args []string) int {
+	flags := watchFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	run := prepareRun(flags, mappingOverrides(flags), boolFlag(flags, "help"), flags.Usage)
+	defer run.close()
+	run.watch = true
+	return organizeDirectory(run)
+}
+
+// rootFlags defines the flags accepted without a command
+func rootFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("go-file-organizer", flag.ContinueOnError)
+	defineOrganizeFlags(flags)
+	flags.Bool("watch", false, "Watch directory for new files and organize them automatically")
+	flags.String("script", "", "With --dry-run, also write the moves as a POSIX shell script to this file")
+	flags.Bool("interactive", false, "Review the planned moves file by file before anything is moved")
+	flags.Bool("tui", false, "Browse and adjust the planned moves in a full-screen terminal UI before anything is moved")
+	flags.Bool("version", false, "Show version information")
+	flags.Bool("help", false, "Show usage")
+	flags.Usage = printUsage
+	return flags
+}
+
+// runRootCommand keeps the flags of the command line before subcommands
+// working: --path organizes, --watch keeps watching and --version prints the version
+func runRootCommand(args []string) int {
+	flags := rootFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if boolFlag(flags, "version") {
+		fmt.Println(version.GetVersionInfo())
+		return exitOK
+	}
+
+	run := prepareRun(flags, mappingOverrides(flags), boolFlag(flags, "help"), printUsage)
+	defer run.close()
+	return organizeDirectory(run)
+}
+
+// organizeDirectory organizes the files of a prepared run, then watches the
+// directory if requested, and returns the exit code
+func organizeDirectory(run *runSetup) int {
+	out := run.events.Text()
+	fmt.Fprintln(out, "Dry run mode:", run.dryRun)
+
+	// Organize files
+	if run.dryRun {
+		fmt.Fprintln(out, "\n🔮 DRY-RUN MODE: Simulating file organization...")
+	} else {
+		fmt.Fprintln(out, "\n🚀 ORGANIZING FILES...")
+	}
+
+	// The shell script is only created once every option has been checked
+	scriptPath := run.settings.String("script")
+	if scriptPath != "" && (!run.dryRun || run.watch) {
+		return reportError(run.events, "--script requires --dry-run and cannot be used with --watch")
+	}
+
+	// A list of files is organized once
+	if run.options.Files != nil && run.watch {
+		return reportError(run.events, "--files-from cannot be used with --watch")
+	}
+
+	// Stop at the first failed move if requested
+	failOnError, err := run.settings.Bool("fail-on-error")
+	if err != nil {
+		return reportErrorCode(run.events, exitConfigError, "%v", err)
+	}
+	run.options.FailOnError = failOnError
+
+	// Let the user confirm every move first if requested
+	interactive, err := run.settings.Bool("interactive")
+	if err != nil {
+		return reportErrorCode(run.events, exitConfigError, "%v", err)
+	}
+	if interactive {
+		if run.watch {
+			return reportError(run.events, "--interactive cannot be used with --watch")
+		}
+		if run.settings.String("files-from") == "-" {
+			return reportError(run.events, "--interactive reads its answers from stdin and cannot be used with --files-from -")
+		}
+		run.options.Review = organizer.NewReviewer(os.Stdin, out).Review
+	}
+
+	// Ctrl+C stops the run between two moves, or ends watch mode
+	ctx, stopListening := interruptContext(out)
+	defer stopListening()
+
+	// Or let them browse and adjust the plan in the terminal UI
+	useTUI, err := run.settings.Bool("tui")
+	if err != nil {
+		return reportErrorCode(run.events, exitConfigError, "%v", err)
+	}
+	if useTUI {
+		if interactive || run.watch {
+			return reportError(run.events, "--tui cannot be used with --interactive or --watch")
+		}
+		if !tui.IsTerminal(os.Stdin) {
+			return reportError(run.events, "--tui needs an interactive terminal")
+		}
+		run.options.Review = tuiReview(newTUIBackend(ctx, run), out)
+	}
+
+	// Export the dry run as a shell script if requested
+	var script *os.File
+	if scriptPath != "" {
+		if script, err = createScript(scriptPath); err != nil {
+			return reportError(run.events, "%v", err)
+		}
+		run.options.Script = script
+	}
+
+	// Record the moves so that the run can be undone
+	if !run.dryRun {
+		run.journal = openJournal(run.settings, run.options.RootPath, out)
+		run.options.Journal = run.journal
+		if run.journal != nil {
+			ignoreOwnFile(run.options.IgnoreManager, run.options.RootPath, journalDir(run.settings))
+		}
+	}
+
+	summary, err := organizer.Organize(ctx, run.options)
+	if script != nil {
+		if closeErr := script.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write shell script: %v", closeErr)
+		}
+	}
+	if errors.Is(err, organizer.ErrReviewAborted) {
+		fmt.Fprintln(out, "\n🛑 Review aborted, nothing was moved")
+		run.events.Finish()
+		return exitError
+	}
+	if err != nil && !organizer.StoppedEarly(err) {
+		return reportError(run.events, "organizing files: %v", err)
+	}
+
+	// Print summary
+	organizer.FprintSummary(out, summary, run.dryRun)
+
+	if run.logger != nil {
+		fmt.Fprintf(out, "\n📝 Detailed log written to: %s\n", run.logPath)
+	}
+	if script != nil {
+		fmt.Fprintf(out, "📜 Shell script written to: %s (review it, then run: sh %s)\n", scriptPath, scriptPath)
+		run.events.Emit(organizer.Event{Type: organizer.EventCreated, Path: scriptPath})
+	}
+
+	code := exitOK
+	if len(summary.Failures) > 0 {
+		code = exitPartialFailure
+	}
+	if err != nil {
+		// Stopped early: the moves made so far are kept and can be undone
+		code = reportStop(out, err)
+	} else if run.watch {
+		// Start watch mode if requested
+		fmt.Fprintf(out, "\n👀 Starting watch mode for directory: %s\n", run.options.RootPath)
+		fmt.Fprintln(out, "Press Ctrl+C to stop watching...")
+
+		// Ctrl+C is how watch mode ends, so it is not reported as an interrupt
+		if err := organizer.Watch(ctx, run.options); errors.Is(err, organizer.ErrMoveFailed) {
+			code = reportStop(out, err)
+		} else if err != nil {
+			return reportError(run.events, "starting watch mode: %v", err)
+		}
+	}
+
+	if run.journal.Moves() > 0 {
+		fmt.Fprintln(out, "↩️  Undo this run with: go-file-organizer undo")
+	}
+	run.events.Finish()
+	return code
+}
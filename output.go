@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+	"io"
+	"os"
+	"time"
+)
+
+// outputMode selects how a command reports its results
+type outputMode string
+
+const (
+	// outputText is the human-readable output (the default)
+	outputText outputMode = "text"
+	// outputJSON writes a single JSON document when the command finishes
+	outputJSON outputMode = "json"
+	// outputNDJSON streams one JSON event per line as the command runs
+	outputNDJSON outputMode = "ndjson"
+)
+
+// parseOutputMode validates an output mode name. An empty name selects text.
+func parseOutputMode(name string) (outputMode, error) {
+	switch mode := outputMode(name); mode {
+	case "":
+		return outputText, nil
+	case outputText, outputJSON, outputNDJSON:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown output mode '%s', expected one of: text, json, ndjson", name)
+	}
+}
+
+// addOutputFlag defines the --output flag on a subcommand's flag set
+func addOutputFlag(flags *flag.FlagSet) {
+	flags.String("output", string(outputText), "Output format: text, json (one document at the end) or ndjson (one event per line)")
+}
+
+// eventOutputFor creates the event writer selected by the "output" setting.
+// It reports an invalid mode on stderr and returns nil.
+func eventOutputFor(settings *utils.Settings) *eventOutput {
+	mode, err := parseOutputMode(settings.String("output"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return nil
+	}
+	return newEventOutput(mode)
+}
+
+// eventOutput writes structured events to stdout in the json and ndjson modes.
+// In those modes the human-readable text goes to stderr, so stdout only ever
+// contains JSON.
+type eventOutput struct {
+	mode    outputMode
+	stdout  io.Writer
+	text    io.Writer
+	events  []organizer.Event
+	summary interface{}
+}
+
+// summaryRecord is the final line of the ndjson stream
+type summaryRecord struct {
+	Type    organizer.EventType `json:"type"`
+	Time    time.Time           `json:"time"`
+	Summary interface{}         `json:"summary"`
+}
+
+// eventDocument is the single document written in json mode
+type eventDocument struct {
+	Events  []organizer.Event `json:"events"`
+	Summary interface{}       `json:"summary"`
+}
+
+// newEventOutput creates an event writer for the given mode
+func newEventOutput(mode outputMode) *eventOutput {
+	eo := &eventOutput{mode: mode, stdout: os.Stdout, text: os.Stdout, events: []organizer.Event{}}
+	if eo.machine() {
+		eo.text = os.Stderr
+	}
+	return eo
+}
+
+// machine reports whether structured output is enabled
+func (eo *eventOutput) machine() bool {
+	return eo.mode == outputJSON || eo.mode == outputNDJSON
+}
+
+// Text returns the writer for human-readable messages
+func (eo *eventOutput) Text() io.Writer {
+	return eo.text
+}
+
+// Emit records an event. Summary events are held back and written by Finish.
+func (eo *eventOutput) Emit(event organizer.Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	switch {
+	case event.Type == organizer.EventSummary:
+		eo.summary = event.Summary
+	case eo.mode == outputNDJSON:
+		eo.writeJSON(event)
+	case eo.mode == outputJSON:
+		eo.events = append(eo.events, event)
+	}
+}
+
+// SetSummary sets the command-specific summary written by Finish
+func (eo *eventOutput) SetSummary(summary interface{}) {
+	eo.summary = summary
+}
+
+// Finish writes the summary line in ndjson mode or the whole document in json mode
+func (eo *eventOutput) Finish() {
+	switch eo.mode {
+	case outputNDJSON:
+		eo.writeJSON(summaryRecord{Type: organizer.EventSummary, Time: time.Now(), Summary: eo.summary})
+	case outputJSON:
+		eo.writeJSON(eventDocument{Events: eo.events, Summary: eo.summary})
+	}
+}
+
+// writeJSON writes one value as a line of JSON to stdout
+func (eo *eventOutput) writeJSON(value interface{}) {
+	if err := json.NewEncoder(eo.stdout).Encode(value); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+	}
+}
@@ -0,0 +1,380 @@
+// Package organizer is the public Go API of go-file-organizer. It sorts the
+// files of a directory into category folders by extension, exactly like the
+// CLI, for programs that embed the organizer instead of running the binary.
+//
+// A run is described by an Options value rather than positional parameters,
+// so that new settings can be added without breaking callers:
+//
+//	summary, err := organizer.Organize(ctx, organizer.Options{
+//		Source:         "/home/me/Downloads",
+//		Mappings:       map[string]string{".md": "Notes"},
+//		Ignore:         []string{"*.part"},
+//		ConflictPolicy: organizer.ConflictRename,
+//		OnEvent:        func(e organizer.Event) { log.Println(e.Type, e.Path) },
+//	})
+//
+// Every run takes a context: cancelling it, or letting its deadline pass,
+// stops the run before the next file. The moves made until then are kept and
+// reported in the returned summary.
+//
+// The result types are shared with the CLI, so a Summary or Plan marshals to
+// the same JSON as its --output json.
+package organizer
+
+import (
+	"context"
+	"fmt"
+	internal "go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+	"io"
+	"io/fs"
+)
+
+// Types shared with the CLI
+type (
+	// Summary counts what a run did: scanned, moved and skipped files, folders
+	// created, bytes and files per category and every failure with its reason
+	Summary = utils.Summary
+	// CategoryStats counts the files and bytes of one category
+	CategoryStats = utils.CategoryStats
+	// Failure is a file or folder that could not be processed
+	Failure = utils.Failure
+	// Plan lists the moves a run intends to make; see BuildPlan and ApplyPlan
+	Plan = internal.Plan
+	// Operation is one planned move
+	Operation = internal.Operation
+	// Stats describes the files of a directory and how much of it is organized
+	Stats = internal.Stats
+	// Event is a structured record of a single action
+	Event = internal.Event
+	// EventType identifies the action an Event records
+	EventType = internal.EventType
+	// Observer follows a run as it happens: the files found by the scan, what
+	// is decided for each of them, the moves, the errors and the summary
+	Observer = internal.Observer
+	// Decision is what a run decided to do with a file: move it to
+	// Destination, or leave it in place for Reason
+	Decision = internal.Decision
+	// NopObserver ignores every notification; embed it to implement only some
+	// methods of Observer
+	NopObserver = internal.NopObserver
+	// ProgressBar is an Observer that renders the moves as a terminal progress bar
+	ProgressBar = internal.ProgressBar
+	// FS is the filesystem a run works on: the tree it scans and the folders
+	// and moves it makes
+	FS = internal.FS
+	// OSFS is the local filesystem, used when Options.FS is nil
+	OSFS = internal.OSFS
+	// MemFS is an in-memory FS, e.g. to test or preview a run without
+	// touching the disk
+	MemFS = internal.MemFS
+	// Classifier decides the category of a file; see Options.Classifiers
+	Classifier = internal.Classifier
+	// ClassifierFunc adapts a function to the Classifier interface
+	ClassifierFunc = internal.ClassifierFunc
+	// Classification is the category a Classifier found for a file, along
+	// with anything else it learned about it
+	Classification = internal.Classification
+	// Candidate is a file to classify: its path, its info and, on demand,
+	// its first HeaderSize bytes
+	Candidate = internal.Candidate
+	// ExtensionClassifier recognizes files by extension; it follows
+	// Options.Classifiers in every chain
+	ExtensionClassifier = internal.ExtensionClassifier
+	// ContentClassifier recognizes files from their content; it ends the chain
+	// with Options.SniffContent
+	ContentClassifier = internal.ContentClassifier
+	// ConflictPolicy decides what happens when a file already exists at the destination
+	ConflictPolicy = utils.ConflictPolicy
+	// UnknownPolicy decides what happens to files without a known category
+	UnknownPolicy = utils.UnknownPolicy
+	// LogSink receives the operation log of a run
+	LogSink = utils.LogSink
+	// LogRecord is one record of the operation log
+	LogRecord = utils.LogRecord
+	// LogFields are the structured fields of a LogRecord
+	LogFields = utils.LogFields
+)
+
+// HeaderSize is the number of leading bytes of a file offered to classifiers
+const HeaderSize = internal.HeaderSize
+
+// Conflict policies
+const (
+	// ConflictError reports the file as a failure and leaves it in place (the default)
+	ConflictError = utils.ConflictError
+	// ConflictSkip leaves the file in place
+	ConflictSkip = utils.ConflictSkip
+	// ConflictRename moves the file under the next free name, e.g. report (1).pdf
+	ConflictRename = utils.ConflictRename
+	// ConflictOverwrite replaces the existing file
+	ConflictOverwrite = utils.ConflictOverwrite
+)
+
+// Unknown file policies
+const (
+	// UnknownLeave leaves files without a known category in place (the default)
+	UnknownLeave = utils.UnknownLeave
+	// UnknownMove moves them to Options.UnknownFolder (default "Misc")
+	UnknownMove = utils.UnknownMove
+	// UnknownGroup moves them to a folder per extension under Options.UnknownFolder (default "Other")
+	UnknownGroup = utils.UnknownGroup
+)
+
+// Event types
+const (
+	// EventPlanned is a move that a dry run would perform
+	EventPlanned = internal.EventPlanned
+	// EventMoved is a file that was moved
+	EventMoved = internal.EventMoved
+	// EventSkipped is a file left in place
+	EventSkipped = internal.EventSkipped
+	// EventError is a file or folder that could not be processed
+	EventError = internal.EventError
+	// EventCreated is a folder that was created
+	EventCreated = internal.EventCreated
+	// EventSummary carries the summary at the end of a run
+	EventSummary = internal.EventSummary
+)
+
+// Reasons a file is left in place, as reported in Decision.Reason
+const (
+	// ReasonIgnored is a file matched by an ignore pattern
+	ReasonIgnored = internal.ReasonIgnored
+	// ReasonCategory is a file whose category is left in place
+	ReasonCategory = internal.ReasonCategory
+	// ReasonOrganized is a file already in its category folder
+	ReasonOrganized = internal.ReasonOrganized
+	// ReasonConflict is a file whose destination exists under the skip policy
+	ReasonConflict = internal.ReasonConflict
+	// ReasonRejected is a planned move rejected by Options.Review
+	ReasonRejected = internal.ReasonRejected
+)
+
+// Errors that end a run early. The moves made before it are kept, and the
+// summary returned with the error describes them.
+var (
+	// ErrMoveFailed is returned, wrapped with the file and the reason, when a
+	// move fails and Options.FailOnError is set
+	ErrMoveFailed = internal.ErrMoveFailed
+	// ErrInterrupted is returned, wrapped together with the context's error,
+	// when the context is done before the run finished
+	ErrInterrupted = internal.ErrInterrupted
+)
+
+// Options describes a run. Source is required; the zero value of every other
+// field selects the default.
+type Options struct {
+	// Source is the directory whose files are organized
+	Source string
+	// Destination is where category folders are created; defaults to Source.
+	// Relative destinations are resolved against Source.
+	Destination string
+	// Files limits the run to these files inside Source instead of every file
+	// under it (nil for every file). Relative names are resolved against Source.
+	Files []string
+
+	// Mappings maps extensions such as ".md" to categories, on top of the
+	// built-in mappings returned by DefaultMappings
+	Mappings map[string]string
+	// Ignore lists patterns of files and directories to leave alone, in the
+	// syntax of .organizerignore
+	Ignore []string
+	// IgnoreFile is an ignore file whose patterns are added to Ignore ("" for none)
+	IgnoreFile string
+	// ConflictPolicy decides what happens when the destination file exists
+	ConflictPolicy ConflictPolicy
+	// UnknownPolicy decides what happens to files without a known category
+	UnknownPolicy UnknownPolicy
+	// UnknownFolder is the folder used by UnknownMove and UnknownGroup
+	UnknownFolder string
+	// SniffContent detects the category of uncategorized files from their content
+	SniffContent bool
+	// SkipCategories lists categories whose files are left in place
+	SkipCategories []string
+	// Classifiers are consulted in order before the extension mappings; the
+	// first one that recognizes a file decides its category (nil for none)
+	Classifiers []Classifier
+
+	// DryRun reports the moves without touching the filesystem
+	DryRun bool
+	// FailOnError stops the run at the first file that cannot be moved
+	// instead of recording the failure and carrying on with the others
+	FailOnError bool
+	// FS is the filesystem of Source (nil for the local filesystem). The
+	// ignore file, journals and plan files are always read from the local one.
+	FS FS
+
+	// OnEvent is called for every action of the run (nil to disable)
+	OnEvent func(Event)
+	// Observer follows the progress of the run, e.g. to render it in a GUI;
+	// combine several with MultiObserver (nil to disable)
+	Observer Observer
+	// Review is called with the plan before any move is made. It can confirm
+	// the plan or change its operations in place; an error aborts the run.
+	Review func(plan *Plan) error
+	// Logger receives the operation log (nil to disable)
+	Logger LogSink
+	// Output receives the human-readable progress text the CLI prints (nil to
+	// discard it)
+	Output io.Writer
+}
+
+// Organize moves the files of opts.Source into category folders and returns
+// what it did. Files that cannot be moved are listed in Summary.Failures
+// unless FailOnError stops the run.
+func Organize(ctx context.Context, opts Options) (*Summary, error) {
+	options, err := opts.build(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	return internal.Organize(ctx, options)
+}
+
+// BuildPlan returns the moves Organize would make, without touching the
+// filesystem. The summary counts the scanned, ignored and skipped files.
+func BuildPlan(ctx context.Context, opts Options) (*Plan, *Summary, error) {
+	options, err := opts.build(opts.Source)
+	if err != nil {
+		return nil, nil, err
+	}
+	return internal.BuildPlan(ctx, options)
+}
+
+// ApplyPlan makes the moves of a plan, e.g. one returned by BuildPlan or
+// LoadPlan. Operations whose source changed since planning are refused and
+// reported as failures. The plan's root and conflict policy are used; the
+// selection options Source, Files, Mappings and Ignore do not apply.
+func ApplyPlan(ctx context.Context, plan *Plan, opts Options) (*Summary, error) {
+	options, err := opts.build(plan.RootPath)
+	if err != nil {
+		return nil, err
+	}
+	return internal.ApplyPlan(ctx, plan, options)
+}
+
+// CollectStats reports the files of opts.Source per category and extension
+// and how many are already organized, without moving anything
+func CollectStats(ctx context.Context, opts Options) (*Stats, error) {
+	options, err := opts.build(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	return internal.CollectStats(ctx, options)
+}
+
+// Watch organizes the new files of opts.Source as they appear, until ctx is
+// done or, with FailOnError, a file cannot be moved. Stopping through ctx is
+// not an error; Watch installs no signal handlers of its own. New files are
+// reported by the local filesystem, so Options.FS must give access to the same tree.
+func Watch(ctx context.Context, opts Options) error {
+	options, err := opts.build(opts.Source)
+	if err != nil {
+		return err
+	}
+	return internal.Watch(ctx, options)
+}
+
+// NewProgressBar returns an Observer that draws the moves of a run as a
+// terminal progress bar on out, like the CLI's --progress
+func NewProgressBar(out io.Writer) *ProgressBar {
+	return internal.NewProgressBar(out)
+}
+
+// NewCandidate returns a Candidate for the file at path, whose header is read
+// from fsys (nil for the local filesystem), e.g. to test a Classifier
+func NewCandidate(fsys FS, path string, info fs.FileInfo) *Candidate {
+	return internal.NewCandidate(fsys, path, info)
+}
+
+// NewMemFS returns an empty in-memory filesystem; fill it with MemFS.WriteFile
+func NewMemFS() *MemFS {
+	return internal.NewMemFS()
+}
+
+// MultiObserver returns an Observer that notifies each of the given observers
+// in turn; nil observers are left out
+func MultiObserver(observers ...Observer) Observer {
+	return internal.MultiObserver(observers...)
+}
+
+// SavePlan writes a plan as indented JSON
+func SavePlan(plan *Plan, path string) error {
+	return internal.SavePlan(plan, path)
+}
+
+// LoadPlan reads and validates a plan file written by SavePlan or the CLI's
+// plan command
+func LoadPlan(path string) (*Plan, error) {
+	return internal.LoadPlan(path)
+}
+
+// DefaultMappings returns a copy of the built-in extension to category mappings
+func DefaultMappings() map[string]string {
+	return internal.GetDefaultExtensionCategories()
+}
+
+// build validates the options and turns them into the organizer's options
+// for a run on root
+func (o Options) build(root string) (internal.Options, error) {
+	if root == "" {
+		return internal.Options{}, fmt.Errorf("source directory is required")
+	}
+	output := o.Output
+	if output == nil {
+		output = io.Discard
+	}
+
+	mapping := utils.NewExtensionMapping(internal.GetDefaultExtensionCategories())
+	mapping.SetOutput(output)
+	for ext, category := range o.Mappings {
+		if err := mapping.SetMapping(ext, category, "api", "Options.Mappings"); err != nil {
+			return internal.Options{}, err
+		}
+	}
+
+	ignoreManager := utils.NewIgnoreManager(root)
+	ignoreManager.SetOutput(output)
+	if o.IgnoreFile != "" {
+		if err := ignoreManager.LoadIgnoreFile(o.IgnoreFile); err != nil {
+			return internal.Options{}, err
+		}
+	}
+	ignoreManager.AddPatterns(o.Ignore)
+
+	conflictPolicy, err := utils.ParseConflictPolicy(string(o.ConflictPolicy))
+	if err != nil {
+		return internal.Options{}, err
+	}
+	unknownPolicy, err := utils.ParseUnknownPolicy(string(o.UnknownPolicy))
+	if err != nil {
+		return internal.Options{}, err
+	}
+	if o.UnknownFolder != "" {
+		if err := utils.ValidateCategory(o.UnknownFolder); err != nil {
+			return internal.Options{}, fmt.Errorf("invalid unknown folder '%s': %v", o.UnknownFolder, err)
+		}
+	}
+
+	return internal.Options{
+		RootPath:         root,
+		Files:            o.Files,
+		Destination:      o.Destination,
+		DryRun:           o.DryRun,
+		Logger:           o.Logger,
+		ExtensionMapping: mapping,
+		IgnoreManager:    ignoreManager,
+		ConflictPolicy:   conflictPolicy,
+		UnknownPolicy:    unknownPolicy,
+		UnknownFolder:    o.UnknownFolder,
+		SniffContent:     o.SniffContent,
+		SkipCategories:   o.SkipCategories,
+		Classifiers:      o.Classifiers,
+		Output:           output,
+		OnEvent:          o.OnEvent,
+		Observer:         o.Observer,
+		Review:           o.Review,
+		FailOnError:      o.FailOnError,
+		FS:               o.FS,
+	}, nil
+}
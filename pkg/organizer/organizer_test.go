@@ -0,0 +1,192 @@
+package organizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, root string, names ...string) {
+	for _, name := range names {
+		path := filepath.Join(root, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(name), 0644))
+	}
+}
+
+func TestOrganize(t *testing.T) {
+	source := t.TempDir()
+	writeFiles(t, source, "notes.md", "report.pdf", "movie.part", filepath.Join("keep", "old.pdf"), "mystery.xyz")
+
+	var events []Event
+	summary, err := Organize(context.Background(), Options{
+		Source:        source,
+		Mappings:      map[string]string{".MD": "Notes"},
+		Ignore:        []string{"*.part", "keep/"},
+		UnknownPolicy: UnknownMove,
+		OnEvent:       func(e Event) { events = append(events, e) },
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, summary.FilesMoved)
+	assert.FileExists(t, filepath.Join(source, "Notes", "notes.md"))
+	assert.FileExists(t, filepath.Join(source, "Documents", "report.pdf"))
+	assert.FileExists(t, filepath.Join(source, "Misc", "mystery.xyz"))
+	assert.FileExists(t, filepath.Join(source, "movie.part"))
+	assert.FileExists(t, filepath.Join(source, "keep", "old.pdf"))
+	if assert.NotEmpty(t, events) {
+		assert.Equal(t, EventSummary, events[len(events)-1].Type)
+	}
+}
+
+func TestOrganizeDryRunAndDestination(t *testing.T) {
+	source := t.TempDir()
+	writeFiles(t, source, "report.pdf")
+
+	summary, err := Organize(context.Background(), Options{Source: source, Destination: "Sorted", DryRun: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.FilesMoved)
+	assert.FileExists(t, filepath.Join(source, "report.pdf"))
+	assert.NoDirExists(t, filepath.Join(source, "Sorted"))
+
+	_, err = Organize(context.Background(), Options{Source: source, Destination: "Sorted"})
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(source, "Sorted", "Documents", "report.pdf"))
+}
+
+func TestOptionsValidation(t *testing.T) {
+	source := t.TempDir()
+
+	_, err := Organize(context.Background(), Options{})
+	assert.ErrorContains(t, err, "source directory is required")
+
+	_, err = Organize(context.Background(), Options{Source: source, Mappings: map[string]string{"pdf": "Documents"}})
+	assert.ErrorContains(t, err, "invalid extension 'pdf'")
+
+	_, err = Organize(context.Background(), Options{Source: source, ConflictPolicy: "merge"})
+	assert.ErrorContains(t, err, "unknown conflict policy 'merge'")
+
+	_, err = Organize(context.Background(), Options{Source: source, UnknownPolicy: "delete"})
+	assert.ErrorContains(t, err, "unknown file policy 'delete'")
+
+	_, err = Organize(context.Background(), Options{Source: source, UnknownPolicy: UnknownMove, UnknownFolder: "../Misc"})
+	assert.ErrorContains(t, err, "invalid unknown folder")
+}
+
+func TestBuildSaveAndApplyPlan(t *testing.T) {
+	source := t.TempDir()
+	writeFiles(t, source, "report.pdf", "photo.jpg", filepath.Join("Documents", "report.pdf"))
+
+	plan, scan, err := BuildPlan(context.Background(), Options{Source: source, ConflictPolicy: ConflictRename})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(plan.Operations))
+	assert.Equal(t, 1, scan.AlreadyOrganized)
+
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	assert.NoError(t, SavePlan(plan, planFile))
+	loaded, err := LoadPlan(planFile)
+	assert.NoError(t, err)
+
+	// The plan's root and conflict policy are used
+	summary, err := ApplyPlan(context.Background(), loaded, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, summary.FilesMoved)
+	assert.FileExists(t, filepath.Join(source, "Documents", "report (1).pdf"))
+	assert.FileExists(t, filepath.Join(source, "Images", "photo.jpg"))
+}
+
+func TestCollectStats(t *testing.T) {
+	source := t.TempDir()
+	writeFiles(t, source, "report.pdf", filepath.Join("Images", "photo.jpg"))
+
+	stats, err := CollectStats(context.Background(), Options{Source: source})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.Files)
+	assert.Equal(t, 1, stats.Organized)
+	assert.Equal(t, 1, stats.Pending)
+}
+
+func TestCancel(t *testing.T) {
+	source := t.TempDir()
+	writeFiles(t, source, "report.pdf")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err := Organize(ctx, Options{Source: source})
+	assert.ErrorIs(t, err, ErrInterrupted)
+	assert.ErrorIs(t, err, context.Canceled)
+	if assert.NotNil(t, summary) {
+		assert.Equal(t, 0, summary.FilesMoved)
+	}
+	assert.FileExists(t, filepath.Join(source, "report.pdf"))
+
+	// Watch stops without an error once its context is done
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.NoError(t, Watch(ctx, Options{Source: source}))
+}
+
+// countingObserver counts the moves of a run
+type countingObserver struct {
+	NopObserver
+	started, moved int
+}
+
+func (c *countingObserver) Started(total int) { c.started = total }
+func (c *countingObserver) Moved(Event)       { c.moved++ }
+
+func TestObserver(t *testing.T) {
+	source := t.TempDir()
+	writeFiles(t, source, "report.pdf", "photo.jpg")
+
+	observer := &countingObserver{}
+	_, err := Organize(context.Background(), Options{Source: source, Observer: observer})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, observer.started)
+	assert.Equal(t, 2, observer.moved)
+}
+
+func TestInMemory(t *testing.T) {
+	source := filepath.Join(string(filepath.Separator), "inbox")
+	fsys := NewMemFS()
+	assert.NoError(t, fsys.WriteFile(filepath.Join(source, "report.pdf"), []byte("report"), 0644))
+
+	summary, err := Organize(context.Background(), Options{Source: source, FS: fsys})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.FilesMoved)
+	data, err := fsys.ReadFile(filepath.Join(source, "Documents", "report.pdf"))
+	assert.NoError(t, err)
+	assert.Equal(t, "report", string(data))
+	assert.NoDirExists(t, source)
+}
+
+func TestClassifiers(t *testing.T) {
+	source := t.TempDir()
+	writeFiles(t, source, "invoice-42.txt", "notes.txt")
+
+	invoices := ClassifierFunc(func(file *Candidate) (Classification, bool) {
+		number, ok := strings.CutPrefix(strings.TrimSuffix(file.Info.Name(), ".txt"), "invoice-")
+		return Classification{Category: "Invoices", Metadata: map[string]string{"number": number}}, ok
+	})
+	plan, _, err := BuildPlan(context.Background(), Options{Source: source, Classifiers: []Classifier{invoices}})
+	assert.NoError(t, err)
+	if assert.Len(t, plan.Operations, 2) {
+		assert.Equal(t, "Documents", plan.Operations[0].Category)
+		assert.Equal(t, "Invoices", plan.Operations[1].Category)
+		assert.Equal(t, "42", plan.Operations[1].Metadata["number"])
+	}
+}
+
+func TestDefaultMappings(t *testing.T) {
+	mappings := DefaultMappings()
+	assert.Equal(t, "Documents", mappings[".pdf"])
+
+	// The copy can be changed freely
+	mappings[".pdf"] = "Changed"
+	assert.Equal(t, "Documents", DefaultMappings()[".pdf"])
+}
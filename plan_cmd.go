@@ -0,0 +1,253 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"os"
+	"path/filepath"
+)
+
+// defaultPlanFile is where plan writes and apply reads the plan by default
+const defaultPlanFile = "organizer-plan.json"
+
+// planSummary is the summary of the plan command in json and ndjson output
+type planSummary struct {
+	PlanFile   string      `json:"planFile"`
+	Operations int         `json:"operations"`
+	Scan       interface{} `json:"scan"`
+}
+
+// planFlags defines the flags of the plan command
+func planFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("plan", flag.ContinueOnError)
+	defineSelectionFlags(flags)
+	defineLogFlags(flags)
+	flags.String("plan-file", defaultPlanFile, "File to write the plan to")
+	flags.Bool("help", false, "Show usage")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer plan --path <directory> [--plan-file file] [--files-from file [-0]] [--profile name] [--map .ext=Category] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runPlanCommand scans a directory and saves the moves it would make to a plan file
+func runPlanCommand(args []string) int {
+	flags := planFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	run := prepareRun(flags, mappingOverrides(flags), boolFlag(flags, "help"), flags.Usage)
+	defer run.close()
+	events := run.events
+	out := events.Text()
+	planFile := run.settings.String("plan-file")
+
+	// Plans are applied later, possibly from another directory
+	if rootPath, err := filepath.Abs(run.options.RootPath); err == nil {
+		run.options.RootPath = rootPath
+	}
+	if run.options.Destination != "" && !filepath.IsAbs(run.options.Destination) {
+		run.options.Destination = filepath.Join(run.options.RootPath, run.options.Destination)
+	}
+
+	fmt.Fprintln(out, "\n📋 PLANNING FILE ORGANIZATION...")
+	// Ctrl+C stops the scan; no plan is written then
+	ctx, stopListening := interruptContext(out)
+	plan, scan, err := organizer.BuildPlan(ctx, run.options)
+	stopListening()
+	if errors.Is(err, organizer.ErrInterrupted) {
+		fmt.Fprintln(out, "\n🛑 Interrupted, no plan was written")
+		events.Finish()
+		return exitInterrupted
+	}
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		events.Emit(organizer.Event{Type: organizer.EventError, Path: run.options.RootPath, Reason: err.Error()})
+		events.Finish()
+		return exitError
+	}
+
+	for _, op := range plan.Operations {
+		fmt.Fprintf(out, "  [PLAN] %s -> %s\n", op.Source, op.Destination)
+		events.Emit(organizer.Event{Type: organizer.EventPlanned, Path: op.Source, Destination: op.Destination, Category: op.Category})
+	}
+
+	if err := organizer.SavePlan(plan, planFile); err != nil {
+		fmt.Fprintf(out, "Error: %v\n", err)
+		events.Emit(organizer.Event{Type: organizer.EventError, Path: planFile, Reason: err.Error()})
+		events.Finish()
+		return exitError
+	}
+	events.Emit(organizer.Event{Type: organizer.EventCreated, Path: planFile})
+	events.SetSummary(planSummary{PlanFile: planFile, Operations: len(plan.Operations), Scan: scan})
+
+	fmt.Fprintf(out, "\n📋 Planned %d move(s) of %d scanned file(s)\n", len(plan.Operations), scan.FilesScanned)
+	fmt.Fprintf(out, "💾 Plan written to: %s\n", planFile)
+	fmt.Fprintf(out, "   Review or edit it, then run: go-file-organizer apply --plan-file %s\n", planFile)
+	events.Finish()
+	return exitOK
+}
+
+// applyFlags defines the flags of the apply command
+func applyFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("apply", flag.ContinueOnError)
+	flags.String("plan-file", defaultPlanFile, "Plan file to execute")
+	flags.Bool("dry-run", false, "Check the plan and preview the moves without performing them")
+	flags.Bool("progress", false, "Show progress bar while applying")
+	flags.String("script", "", "With --dry-run, also write the moves as a POSIX shell script to this file")
+	flags.Bool("interactive", false, "Review the planned moves file by file before anything is moved")
+	flags.Bool("fail-on-error", false, "Stop at the first file that cannot be moved instead of carrying on")
+	defineLogFlags(flags)
+	defineJournalFlag(flags)
+	addOutputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer apply [--plan-file file] [--dry-run [--script file]] [--interactive] [--progress] [--fail-on-error] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runApplyCommand executes a plan file written by the plan command
+func runApplyCommand(args []string) int {
+	flags := applyFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	settings := gatherSettings(flags, nil)
+	events := eventOutputFor(settings)
+	if events == nil {
+		return exitUsage
+	}
+	out := events.Text()
+	planFile := settings.String("plan-file")
+
+	// fail reports an error and writes the structured output before exiting
+	fail := func(path, format string, args ...interface{}) int {
+		message := fmt.Sprintf(format, args...)
+		fmt.Fprintf(out, "Error: %s\n", message)
+		events.Emit(organizer.Event{Type: organizer.EventError, Path: path, Reason: message})
+		events.Finish()
+		return exitError
+	}
+
+	dryRun, err := settings.Bool("dry-run")
+	if err != nil {
+		return reportErrorCode(events, exitConfigError, "%v", err)
+	}
+	progress, err := settings.Bool("progress")
+	if err != nil {
+		return reportErrorCode(events, exitConfigError, "%v", err)
+	}
+	interactive, err := settings.Bool("interactive")
+	if err != nil {
+		return reportErrorCode(events, exitConfigError, "%v", err)
+	}
+	failOnError, err := settings.Bool("fail-on-error")
+	if err != nil {
+		return reportErrorCode(events, exitConfigError, "%v", err)
+	}
+
+	plan, err := organizer.LoadPlan(planFile)
+	if err != nil {
+		return fail(planFile, "%v", err)
+	}
+
+	opts := organizer.Options{
+		RootPath:     plan.RootPath,
+		DryRun:       dryRun,
+		ShowProgress: progress,
+		FailOnError:  failOnError,
+		Output:       out,
+	}
+	if events.machine() {
+		opts.OnEvent = events.Emit
+	}
+	if interactive {
+		opts.Review = organizer.NewReviewer(os.Stdin, out).Review
+	}
+	if !dryRun {
+		logger, _, err := openLogger(settings, out)
+		if err != nil {
+			return reportErrorCode(events, exitConfigError, "%v", err)
+		}
+		if logger != nil {
+			defer logger.Close()
+			opts.Logger = logger
+		}
+
+		// Record the moves so that the run can be undone
+		if journal := openJournal(settings, plan.RootPath, out); journal != nil {
+			defer func() {
+				if err := journal.Close(); err != nil {
+					fmt.Fprintf(out, "Warning: %v\n", err)
+				}
+			}()
+			opts.Journal = journal
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "\n🔮 DRY-RUN MODE: Checking %d planned move(s) from %s...\n", len(plan.Operations), planFile)
+	} else {
+		fmt.Fprintf(out, "\n🚀 APPLYING %d PLANNED MOVE(S) FROM %s...\n", len(plan.Operations), planFile)
+	}
+
+	// Export the checked plan as a shell script if requested
+	scriptPath := settings.String("script")
+	var script *os.File
+	if scriptPath != "" {
+		if !dryRun {
+			return fail("", "--script requires --dry-run")
+		}
+		if script, err = createScript(scriptPath); err != nil {
+			return fail(scriptPath, "%v", err)
+		}
+		opts.Script = script
+	}
+
+	// Ctrl+C stops the run between two moves
+	ctx, stopListening := interruptContext(out)
+	summary, err := organizer.ApplyPlan(ctx, plan, opts)
+	stopListening()
+	if script != nil {
+		if closeErr := script.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write shell script: %v", closeErr)
+		}
+	}
+	if errors.Is(err, organizer.ErrReviewAborted) {
+		fmt.Fprintln(out, "\n🛑 Review aborted, nothing was moved")
+		events.Finish()
+		return exitError
+	}
+	if err != nil && !organizer.StoppedEarly(err) {
+		return fail(planFile, "applying plan: %v", err)
+	}
+	organizer.FprintSummary(out, summary, dryRun)
+	if script != nil {
+		fmt.Fprintf(out, "📜 Shell script written to: %s (review it, then run: sh %s)\n", scriptPath, scriptPath)
+		events.Emit(organizer.Event{Type: organizer.EventCreated, Path: scriptPath})
+	}
+	code := exitOK
+	if len(summary.Failures) > 0 {
+		code = exitPartialFailure
+	}
+	if err != nil {
+		code = reportStop(out, err)
+	}
+	if opts.Journal.Moves() > 0 {
+		fmt.Fprintln(out, "↩️  Undo this run with: go-file-organizer undo")
+	}
+	events.Finish()
+	return code
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// nonSettingFlags are flags that are actions or lists rather than settings
+var nonSettingFlags = map[string]bool{"help": true, "version": true, "map": true}
+
+// flagAliases maps short flags to the setting they stand for
+var flagAliases = map[string]string{"0": "null", "v": "verbose"}
+
+// gatherSettings collects the settings defined by a flag set with precedence
+// default < environment < CLI. Config file values are layered in by loadConfig.
+// Extra settings without a flag can be given with their default values.
+func gatherSettings(flags *flag.FlagSet, extraDefaults map[string]string) *utils.Settings {
+	settings := utils.NewSettings()
+	var names []string
+
+	flags.VisitAll(func(f *flag.Flag) {
+		if !nonSettingFlags[f.Name] && flagAliases[f.Name] == "" {
+			settings.Set(f.Name, f.DefValue, utils.SourceDefault)
+			names = append(names, f.Name)
+		}
+	})
+	for name, value := range extraDefaults {
+		settings.Set(name, value, utils.SourceDefault)
+		names = append(names, name)
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		if alias := flagAliases[f.Name]; alias != "" {
+			settings.Set(alias, f.Value.String(), utils.SourceCLI)
+		} else if !nonSettingFlags[f.Name] {
+			settings.Set(f.Name, f.Value.String(), utils.SourceCLI)
+		}
+	})
+	settings.ApplyEnv(names, os.LookupEnv)
+
+	return settings
+}
+
+// loadConfig loads the config file selected by the settings, or the first default
+// candidate that exists, and layers its run settings in. It returns nil if no
+// config file exists.
+func loadConfig(settings *utils.Settings) (*utils.Config, error) {
+	configPath := configFilePath(settings)
+	if configPath == "" {
+		return nil, nil
+	}
+
+	config, err := utils.ReadConfigFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.ApplyConfig(config)
+	return config, nil
+}
+
+// configFilePath returns the config file selected by --config or GFO_CONFIG, or
+// the first default candidate that exists, or "" if there is none
+func configFilePath(settings *utils.Settings) string {
+	if configPath := settings.String("config"); configPath != "" {
+		return configPath
+	}
+	return utils.FindConfigFile(defaultConfigCandidates...)
+}
+
+// buildExtensionMapping merges the mapping sources in precedence order: built-in
+// defaults, config file, selected profile, GFO_MAP and finally --map flags.
+// Informational messages are written to output.
+func buildExtensionMapping(config *utils.Config, profileName string, mapOverrides []string, output io.Writer) (*utils.ExtensionMapping, *utils.Profile, error) {
+	extensionMapping := utils.NewExtensionMapping(organizer.GetDefaultExtensionCategories())
+	extensionMapping.SetOutput(output)
+	if config != nil {
+		extensionMapping.ApplyConfig(config)
+	}
+
+	// Apply the selected profile on top of the top-level config
+	profile := &utils.Profile{}
+	if profileName != "" {
+		if config == nil {
+			return nil, nil, fmt.Errorf("profile '%s' requested but no config file was loaded", profileName)
+		}
+
+		resolved, err := config.ResolveProfile(profileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := extensionMapping.ApplyProfile(resolved); err != nil {
+			return nil, nil, fmt.Errorf("error applying profile '%s': %v", profileName, err)
+		}
+		profile = resolved
+		fmt.Fprintln(output, "Using profile:", profileName)
+	}
+
+	// Apply environment mapping overrides
+	if envMappings, exists := os.LookupEnv(utils.EnvName("map")); exists {
+		if err := extensionMapping.ApplyEnvMappings(envMappings); err != nil {
+			return nil, nil, fmt.Errorf("error applying environment mappings: %v", err)
+		}
+	}
+
+	// Apply CLI mapping overrides
+	if len(mapOverrides) > 0 {
+		if err := extensionMapping.ApplyCLIMappings(mapOverrides); err != nil {
+			return nil, nil, fmt.Errorf("error applying CLI mappings: %v", err)
+		}
+	}
+
+	return extensionMapping, profile, nil
+}
+
+// ignoreOwnFile adds an anchored ignore pattern for a file the tool writes, such
+// as its log file, if it lies inside the directory being organized
+func ignoreOwnFile(ignoreManager *utils.IgnoreManager, rootPath, filePath string) {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return
+	}
+	absFile, err := filepath.Abs(filePath)
+	if err != nil {
+		return
+	}
+
+	rel, err := filepath.Rel(absRoot, absFile)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return
+	}
+	ignoreManager.AddPatterns([]string{"/" + filepath.ToSlash(rel)})
+}
+
+// listedFromWorkingDir rewrites the names of a file list, which are relative to
+// the working directory, for the organizer, which resolves them against the
+// directory being organized: names inside it become relative to it and the
+// others absolute
+func listedFromWorkingDir(rootPath string, files []string) []string {
+	absRoot, err := filepath.Abs(rootPath)
+	if err != nil {
+		return files
+	}
+
+	resolved := make([]string, len(files))
+	for i, file := range files {
+		resolved[i] = file
+		absFile, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		resolved[i] = absFile
+		if rel, err := filepath.Rel(absRoot, absFile); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			resolved[i] = rel
+		}
+	}
+	return resolved
+}
+
+// defineSelectionFlags defines the flags that decide which files go where,
+// shared by the organize and plan commands
+func defineSelectionFlags(flags *flag.FlagSet) {
+	flags.String("path", "", "Path to the folder to organize")
+	flags.String("profile", "", "Named profile from the config file to use")
+	flags.String("config", "", "Config file to load (default: first of config/config.{json,yaml,yml,toml})")
+	flags.String("ignore-file", ".organizerignore", "Ignore file with patterns to skip")
+	flags.String("unknown", "leave", "What to do with files of unknown type: leave, move (to --unknown-folder) or group (by extension)")
+	flags.String("unknown-folder", "", "Folder for unknown files (default: Misc for move, Other for group)")
+	flags.Bool("sniff", false, "Detect the type of unknown files from their content before applying --unknown")
+	flags.String("skip-category", "", "Comma separated categories whose files are left in place")
+	flags.String("files-from", "", "Only take the files listed in this file, one per line ('-' for stdin), instead of every file under --path")
+	null := flags.Bool("null", false, "With --files-from, the names are separated by NUL characters, as written by find -print0")
+	flags.BoolVar(null, "0", false, "Short for --null")
+	addOutputFlag(flags)
+	flags.Var(&arrayFlags{}, "map", "Override extension mappings (format: .ext=Category, can be used multiple times)")
+}
+
+// mappingOverrides returns the --map values collected by a parsed flag set
+func mappingOverrides(flags *flag.FlagSet) arrayFlags {
+	return *flags.Lookup("map").Value.(*arrayFlags)
+}
+
+// boolFlag returns the value of a boolean flag of a parsed flag set
+func boolFlag(flags *flag.FlagSet, name string) bool {
+	return flags.Lookup(name).Value.String() == "true"
+}
+
+// defineLogFlags defines the flags that control the operation log
+func defineLogFlags(flags *flag.FlagSet) {
+	flags.String("log-file", "", "Log file (default: $XDG_STATE_HOME/go-file-organizer/organizer.log)")
+	flags.Bool("no-log", false, "Do not write a log file")
+	flags.String("log-level", "info", "Minimum level of log records: debug, info, warn or error")
+	flags.String("log-format", string(utils.LogFormatText), "Log record format: text or json")
+	flags.Int("log-max-size", 10, "Rotate the log file when it reaches this size in MiB (0 to never rotate)")
+	flags.Int("log-max-backups", 5, "Number of rotated log files to keep (0 to keep all)")
+	flags.Int("log-max-age", 0, "Rotate the log file and remove rotated ones after this many days (0 to keep them)")
+	flags.Bool("log-compress", false, "Gzip rotated log files")
+}
+
+// loggerOptions reads the log level, format and rotation settings
+func loggerOptions(settings *utils.Settings) (utils.LoggerOptions, error) {
+	level, err := utils.ParseLogLevel(settings.String("log-level"))
+	if err != nil {
+		return utils.LoggerOptions{}, err
+	}
+	format, err := utils.ParseLogFormat(settings.String("log-format"))
+	if err != nil {
+		return utils.LoggerOptions{}, err
+	}
+
+	maxSize, err := settings.Int("log-max-size")
+	if err != nil {
+		return utils.LoggerOptions{}, err
+	}
+	maxBackups, err := settings.Int("log-max-backups")
+	if err != nil {
+		return utils.LoggerOptions{}, err
+	}
+	maxAge, err := settings.Int("log-max-age")
+	if err != nil {
+		return utils.LoggerOptions{}, err
+	}
+	compress, err := settings.Bool("log-compress")
+	if err != nil {
+		return utils.LoggerOptions{}, err
+	}
+
+	return utils.LoggerOptions{
+		Level:  level,
+		Format: format,
+		Rotate: utils.RotateOptions{
+			MaxSize:    int64(maxSize) * 1024 * 1024,
+			MaxBackups: maxBackups,
+			MaxAge:     time.Duration(maxAge) * 24 * time.Hour,
+			Compress:   compress,
+		},
+	}, nil
+}
+
+// openLogger creates the run's logger from the log settings. It returns a nil
+// logger if logging is disabled, and reports a log file that cannot be opened
+// as a warning so that the run can continue without logging.
+func openLogger(settings *utils.Settings, out io.Writer) (*utils.Logger, string, error) {
+	logOptions, err := loggerOptions(settings)
+	if err != nil {
+		return nil, "", err
+	}
+	noLog, err := settings.Bool("no-log")
+	if err != nil {
+		return nil, "", err
+	}
+	if noLog {
+		return nil, "", nil
+	}
+
+	logPath := settings.String("log-file")
+	if logPath == "" {
+		logPath = utils.DefaultLogPath()
+	}
+	logger, err := utils.NewLoggerWithOptions(logPath, logOptions)
+	if err != nil {
+		fmt.Fprintf(out, "Warning: Could not create log file: %v\n", err)
+		fmt.Fprintln(out, "Continuing without logging...")
+		return nil, logPath, nil
+	}
+	return logger, logPath, nil
+}
+
+// runSetup holds everything an organize or plan run needs once flags, config
+// file and environment have been merged
+type runSetup struct {
+	settings *utils.Settings
+	events   *eventOutput
+	options  organizer.Options
+	logger   *utils.Logger
+	logPath  string
+	journal  *organizer.Journal
+	dryRun   bool
+	watch    bool
+}
+
+// close releases the log file and the journal
+func (r *runSetup) close() {
+	if err := r.journal.Close(); err != nil {
+		fmt.Fprintf(r.events.Text(), "Warning: %v\n", err)
+	}
+	r.logger.Close()
+}
+
+// prepareRun merges the settings of a parsed flag set and builds the organizer
+// options and the logger. It prints usage and exits if help was requested or no
+// path was given, and exits on invalid settings.
+func prepareRun(flags *flag.FlagSet, mapOverrides []string, help bool, usage func()) *runSetup {
+	run := prepareSelection(flags, mapOverrides, help, usage, "Organizing path:")
+
+	// Initialize logger
+	logger, logPath, err := openLogger(run.settings, run.events.Text())
+	if err != nil {
+		exitWithError(run.events, exitConfigError, "%v", err)
+	}
+
+	// Never organize the log file that is being written
+	if logger != nil {
+		ignoreOwnFile(run.options.IgnoreManager, run.options.RootPath, logPath)
+		run.options.Logger = logger
+	}
+	run.logger = logger
+	run.logPath = logPath
+	return run
+}
+
+// prepareSelection merges the settings of a parsed flag set and builds the
+// organizer options that decide which files go where, without a logger. The
+// heading is printed with the path.
+func prepareSelection(flags *flag.FlagSet, mapOverrides []string, help bool, usage func(), heading string) *runSetup {
+	// Gather settings with precedence: default < config file < environment < CLI
+	settings := gatherSettings(flags, nil)
+	config, configErr := loadConfig(settings)
+
+	// Human-readable text goes to stderr when structured output is requested
+	events := eventOutputFor(settings)
+	if events == nil {
+		os.Exit(exitUsage)
+	}
+	out := events.Text()
+
+	if configErr != nil {
+		// A config file that was asked for must load; a discovered one may not
+		if settings.String("config") != "" {
+			exitWithError(events, exitConfigError, "could not load config file: %v", configErr)
+		}
+		fmt.Fprintf(out, "Warning: Could not load config file: %v\n", configErr)
+		fmt.Fprintln(out, "Continuing with default mappings...")
+	}
+
+	// Boolean settings may come from the environment, so validate them up front
+	for _, name := range []string{"dry-run", "progress", "watch", "sniff", "null"} {
+		if _, err := settings.Bool(name); err != nil {
+			exitWithError(events, exitConfigError, "%v", err)
+		}
+	}
+
+	path := settings.String("path")
+	dryRun, _ := settings.Bool("dry-run")
+	progress, _ := settings.Bool("progress")
+	watch, _ := settings.Bool("watch")
+	profileName := settings.String("profile")
+	sniff, _ := settings.Bool("sniff")
+
+	unknownPolicy, err := utils.ParseUnknownPolicy(settings.String("unknown"))
+	if err != nil {
+		exitWithError(events, exitConfigError, "%v", err)
+	}
+	unknownFolder := settings.String("unknown-folder")
+	if unknownFolder != "" {
+		if err := utils.ValidateCategory(unknownFolder); err != nil {
+			exitWithError(events, exitConfigError, "invalid unknown folder '%s': %v", unknownFolder, err)
+		}
+	}
+
+	if help || path == "" {
+		usage()
+		os.Exit(exitOK)
+	}
+
+	fmt.Fprintln(out, heading, path)
+	if info, err := os.Stat(path); err != nil {
+		exitWithError(events, exitPathError, "cannot access path: %v", err)
+	} else if !info.IsDir() {
+		exitWithError(events, exitPathError, "path '%s' is not a directory", path)
+	}
+
+	// Take only the listed files if requested
+	var files []string
+	if filesFrom := settings.String("files-from"); filesFrom != "" {
+		null, _ := settings.Bool("null")
+		if files, err = utils.ReadFileListFrom(filesFrom, null); err != nil {
+			exitWithError(events, exitError, "%v", err)
+		}
+		files = listedFromWorkingDir(path, files)
+		fmt.Fprintf(out, "Files from %s: %d\n", filesFrom, len(files))
+	}
+
+	// Initialize configuration
+	extensionMapping, profile, err := buildExtensionMapping(config, profileName, mapOverrides, out)
+	if err != nil {
+		exitWithError(events, exitConfigError, "%v", err)
+	}
+
+	// Initialize ignore manager
+	ignoreManager := utils.NewIgnoreManager(path)
+	ignoreManager.SetOutput(out)
+	ignoreFilePath := settings.String("ignore-file")
+	if err := ignoreManager.LoadIgnoreFile(ignoreFilePath); err != nil {
+		fmt.Fprintf(out, "Warning: Could not load ignore file: %v\n", err)
+		fmt.Fprintln(out, "Continuing without ignore rules...")
+	}
+	ignoreManager.AddPatterns(profile.IgnorePatterns)
+
+	// Print summary of custom rules
+	if len(mapOverrides) > 0 || os.Getenv(utils.EnvName("map")) != "" {
+		extensionMapping.PrintSummary()
+		ignoreManager.PrintSummary()
+	}
+
+	run := &runSetup{
+		settings: settings,
+		events:   events,
+		dryRun:   dryRun,
+		watch:    watch,
+		options: organizer.Options{
+			RootPath:         path,
+			Files:            files,
+			Destination:      profile.Destination,
+			DryRun:           dryRun,
+			ExtensionMapping: extensionMapping,
+			IgnoreManager:    ignoreManager,
+			ShowProgress:     progress,
+			ConflictPolicy:   profile.ConflictPolicy,
+			UnknownPolicy:    unknownPolicy,
+			UnknownFolder:    unknownFolder,
+			SniffContent:     sniff,
+			SkipCategories:   settings.List("skip-category"),
+			Output:           out,
+		},
+	}
+	if events.machine() {
+		run.options.OnEvent = events.Emit
+	}
+	return run
+}
+
+// defineJournalFlag defines the flag that selects where journals are kept
+func defineJournalFlag(flags *flag.FlagSet) {
+	flags.String("journal-dir", "", "Directory of the journals used by undo (default: $XDG_STATE_HOME/go-file-organizer/journal)")
+}
+
+// journalDir returns the journal directory selected by the settings
+func journalDir(settings *utils.Settings) string {
+	if dir := settings.String("journal-dir"); dir != "" {
+		return dir
+	}
+	return organizer.DefaultJournalDir()
+}
+
+// openJournal starts the journal of a run on rootPath. A journal that cannot
+// be created is reported as a warning and the run continues without one.
+func openJournal(settings *utils.Settings, rootPath string, out io.Writer) *organizer.Journal {
+	journal, err := organizer.CreateJournal(journalDir(settings), rootPath)
+	if err != nil {
+		fmt.Fprintf(out, "Warning: Could not create journal: %v\n", err)
+		fmt.Fprintln(out, "Continuing without undo support...")
+		return nil
+	}
+	return journal
+}
+
+// createScript creates an executable file for a dry-run shell script
+func createScript(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shell script: %v", err)
+	}
+	return file, nil
+}
+
+// interruptContext returns a context that is cancelled when the user presses
+// Ctrl+C or the process receives SIGTERM, so that a run stops between two
+// moves rather than in the middle of one. A second signal terminates the
+// process as usual. The returned function stops listening.
+func interruptContext(out io.Writer) (context.Context, func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			fmt.Fprintln(out, "\n🛑 Interrupted, stopping after the current file (press Ctrl+C again to quit now)")
+			cancel()
+		case <-done:
+		}
+	}()
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
+
+// reportStop explains why a run stopped before its last move and returns the
+// exit code for it
+func reportStop(out io.Writer, err error) int {
+	if errors.Is(err, organizer.ErrInterrupted) {
+		fmt.Fprintln(out, "\n🛑 Interrupted, the remaining files were left in place")
+		return exitInterrupted
+	}
+	fmt.Fprintf(out, "\n🛑 Stopped at the first failure (--fail-on-error): %v\n", err)
+	return exitPartialFailure
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+)
+
+// statsFlags defines the flags of the stats command
+func statsFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	defineSelectionFlags(flags)
+	flags.Bool("help", false, "Show usage")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer stats --path <directory> [--files-from file [-0]] [--profile name] [--map .ext=Category] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runStatsCommand reports the files of a directory per category and extension
+// and how many of them an organize run would move
+func runStatsCommand(args []string) int {
+	flags := statsFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	run := prepareSelection(flags, mappingOverrides(flags), boolFlag(flags, "help"), flags.Usage, "Scanning path:")
+	events := run.events
+
+	// Ctrl+C stops the scan
+	ctx, stopListening := interruptContext(events.Text())
+	stats, err := organizer.CollectStats(ctx, run.options)
+	stopListening()
+	if errors.Is(err, organizer.ErrInterrupted) {
+		fmt.Fprintln(events.Text(), "\n🛑 Interrupted, no statistics were collected")
+		events.Finish()
+		return exitInterrupted
+	}
+	if err != nil {
+		return reportError(events, "%v", err)
+	}
+
+	organizer.FprintStats(events.Text(), stats)
+	events.SetSummary(stats)
+	events.Finish()
+	return exitOK
+}
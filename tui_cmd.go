@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/tui"
+	"go-file-organizer/internal/utils"
+	"io"
+	"os"
+)
+
+// tuiBackend lets the terminal UI rebuild the plan of a run and saves the
+// mappings and ignore patterns added in it to the config and ignore files
+type tuiBackend struct {
+	ctx      context.Context
+	options  organizer.Options
+	settings *utils.Settings
+}
+
+// newTUIBackend creates a backend planning with the options of a run, until
+// ctx is done. The mapping and ignore manager are shared, so additions apply
+// to the run too.
+func newTUIBackend(ctx context.Context, run *runSetup) *tuiBackend {
+	options := run.options
+	options.Logger = nil
+	options.OnEvent = nil
+	options.Observer = nil
+	options.Output = io.Discard
+	options.Script = nil
+	options.Journal = nil
+	options.Review = nil
+	return &tuiBackend{ctx: ctx, options: options, settings: run.settings}
+}
+
+// Plan scans the directory again with the current mappings and ignore patterns
+func (b *tuiBackend) Plan() (*organizer.Plan, error) {
+	plan, _, err := organizer.BuildPlan(b.ctx, b.options)
+	return plan, err
+}
+
+// AddMapping saves a mapping to the config file and applies it to the run
+func (b *tuiBackend) AddMapping(ext, category string) (string, error) {
+	configPath := configFilePath(b.settings)
+	if configPath == "" {
+		configPath = defaultConfigCandidates[0]
+	}
+
+	if err := utils.SetConfigMapping(configPath, ext, category); err != nil {
+		return "", err
+	}
+	if err := b.options.ExtensionMapping.SetMapping(ext, category, "config", configPath); err != nil {
+		return "", err
+	}
+	return configPath, nil
+}
+
+// AddIgnorePattern saves a pattern to the ignore file and applies it to the run
+func (b *tuiBackend) AddIgnorePattern(pattern string) (string, error) {
+	ignoreFile := b.settings.String("ignore-file")
+	if err := utils.AppendIgnorePattern(ignoreFile, pattern); err != nil {
+		return "", err
+	}
+	b.options.IgnoreManager.AddPatterns([]string{pattern})
+	return ignoreFile, nil
+}
+
+// tuiReview returns a review step that shows the plan in the terminal UI. The
+// plan keeps the moves accepted there; quitting aborts the run.
+func tuiReview(backend tui.Backend, out io.Writer) func(plan *organizer.Plan) error {
+	return func(plan *organizer.Plan) error {
+		model := tui.NewModel(plan, backend)
+		if err := tui.Run(model, os.Stdin, out); err != nil {
+			return err
+		}
+
+		operations, apply := model.Result()
+		if !apply {
+			return organizer.ErrReviewAborted
+		}
+		plan.Operations = operations
+		return nil
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go-file-organizer/internal/organizer"
+	"go-file-organizer/internal/utils"
+)
+
+// undoSummary is the summary of the undo command in json and ndjson output
+type undoSummary struct {
+	Run      string         `json:"run"`
+	RootPath string         `json:"rootPath"`
+	Restored int            `json:"restored"`
+	Failed   int            `json:"failed"`
+	Summary  *utils.Summary `json:"summary"`
+}
+
+// runListSummary describes a journaled run in the output of undo --list
+type runListSummary struct {
+	ID       string `json:"id"`
+	RootPath string `json:"rootPath"`
+	Started  string `json:"started"`
+	Moves    int    `json:"moves"`
+	Undone   bool   `json:"undone"`
+}
+
+// undoFlags defines the flags of the undo command
+func undoFlags() *flag.FlagSet {
+	flags := flag.NewFlagSet("undo", flag.ContinueOnError)
+	defineJournalFlag(flags)
+	flags.String("run", "", "ID of the run to undo (default: the most recent run not yet undone)")
+	flags.Bool("list", false, "List the journaled runs instead of undoing one")
+	flags.Bool("dry-run", false, "Check which moves can be undone without moving anything")
+	defineLogFlags(flags)
+	addOutputFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: go-file-organizer undo [--list] [--run id] [--dry-run] [--journal-dir dir] [--output text|json|ndjson]")
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// runUndoCommand moves the files of a journaled run back to where they came from
+func runUndoCommand(args []string) int {
+	flags := undoFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	settings := gatherSettings(flags, nil)
+	events := eventOutputFor(settings)
+	if events == nil {
+		return exitUsage
+	}
+	out := events.Text()
+	dir := journalDir(settings)
+
+	if boolFlag(flags, "list") {
+		return listRuns(events, dir)
+	}
+
+	dryRun, err := settings.Bool("dry-run")
+	if err != nil {
+		return reportErrorCode(events, exitConfigError, "%v", err)
+	}
+
+	run, err := organizer.FindJournal(dir, settings.String("run"))
+	if err != nil {
+		return reportError(events, "%v", err)
+	}
+
+	opts := organizer.Options{
+		RootPath: run.RootPath,
+		DryRun:   dryRun,
+		Output:   out,
+	}
+	if events.machine() {
+		opts.OnEvent = events.Emit
+	}
+	if !dryRun {
+		logger, _, err := openLogger(settings, out)
+		if err != nil {
+			return reportErrorCode(events, exitConfigError, "%v", err)
+		}
+		if logger != nil {
+			defer logger.Close()
+			opts.Logger = logger
+		}
+	}
+
+	if dryRun {
+		fmt.Fprintf(out, "\n🔮 DRY-RUN MODE: Checking %d move(s) of run %s in %s...\n", len(run.Moves), run.ID, run.RootPath)
+	} else {
+		fmt.Fprintf(out, "\n↩️  UNDOING %d MOVE(S) OF RUN %s IN %s...\n", len(run.Moves), run.ID, run.RootPath)
+	}
+
+	total := len(run.Moves)
+	// Ctrl+C stops the undo between two moves
+	ctx, stopListening := interruptContext(out)
+	summary, err := organizer.Undo(ctx, run, opts)
+	stopListening()
+	if err != nil && !organizer.StoppedEarly(err) {
+		return reportError(events, "undoing run %s: %v", run.ID, err)
+	}
+
+	failed := len(summary.Failures)
+	if dryRun {
+		fmt.Fprintf(out, "\n🔮 %d of %d move(s) can be undone\n", summary.FilesMoved, total)
+	} else {
+		fmt.Fprintf(out, "\n↩️  Restored %d of %d file(s)\n", summary.FilesMoved, total)
+	}
+	if failed > 0 {
+		fmt.Fprintln(out, "❌ Failures:")
+		for _, failure := range summary.Failures {
+			fmt.Fprintf(out, "    %s: %s\n", failure.Path, failure.Reason)
+		}
+		if !dryRun {
+			fmt.Fprintf(out, "The %d remaining move(s) stay in the journal; fix them and run undo --run %s again.\n", failed, run.ID)
+		}
+	}
+	events.SetSummary(undoSummary{Run: run.ID, RootPath: run.RootPath, Restored: summary.FilesMoved, Failed: failed, Summary: summary})
+
+	if err != nil {
+		// The moves not undone yet stay in the journal
+		code := reportStop(out, err)
+		if !dryRun {
+			fmt.Fprintf(out, "Continue with: go-file-organizer undo --run %s\n", run.ID)
+		}
+		events.Finish()
+		return code
+	}
+	events.Finish()
+
+	if failed > 0 {
+		return exitPartialFailure
+	}
+	return exitOK
+}
+
+// listRuns prints the journaled runs, newest first
+func listRuns(events *eventOutput, dir string) int {
+	out := events.Text()
+	runs, err := organizer.ListJournals(dir)
+	if err != nil {
+		return reportError(events, "%v", err)
+	}
+
+	listed := []runListSummary{}
+	if len(runs) == 0 {
+		fmt.Fprintf(out, "No journaled runs in %s\n", dir)
+	}
+	for _, run := range runs {
+		started := run.Started.Format("2006-01-02 15:04:05")
+		status := ""
+		if run.Undone {
+			status = " (undone)"
+		}
+		fmt.Fprintf(out, "%s  %s  %4d move(s)  %s%s\n", run.ID, started, len(run.Moves), run.RootPath, status)
+		listed = append(listed, runListSummary{ID: run.ID, RootPath: run.RootPath, Started: started, Moves: len(run.Moves), Undone: run.Undone})
+	}
+	events.SetSummary(listed)
+	events.Finish()
+	return exitOK
+}